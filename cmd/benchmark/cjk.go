@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"log-project/internal/db"
+	"log-project/search"
+)
+
+// cjkTerms are Japanese search terms taken from sample content.
+type cjkTerms struct {
+	Char   string // most frequent single character
+	Bigram string // most frequent two-character sequence
+	Phrase string // four consecutive characters from one document
+}
+
+// discoverCJKTerms samples logs the same way discoverTerms does and picks
+// Japanese terms from their content_cjk bigrams.
+func discoverCJKTerms(ctx context.Context, q *db.Queries) (cjkTerms, error) {
+	logs, err := q.ListLogs(ctx, db.ListLogsParams{Limit: 1000, Offset: 0})
+	if err != nil {
+		return cjkTerms{}, err
+	}
+
+	var terms cjkTerms
+	chars := make(map[string]int)
+	bigrams := make(map[string]int)
+	for i, l := range logs {
+		cjk := search.ContentCJK(l.Content)
+		if !cjk.Valid {
+			continue
+		}
+		// Every character of a run starts exactly one token: a bigram, or
+		// the single character closing the run.
+		tokens := strings.Fields(cjk.String)
+		for j, tok := range tokens {
+			first, _ := utf8.DecodeRuneInString(tok)
+			chars[string(first)]++
+			if utf8.RuneCountInString(tok) != 2 {
+				continue
+			}
+			bigrams[tok]++
+
+			// Three bigrams in a row are four characters of one run
+			if terms.Phrase == "" && i >= len(logs)/2 && j+2 < len(tokens) &&
+				utf8.RuneCountInString(tokens[j+1]) == 2 && utf8.RuneCountInString(tokens[j+2]) == 2 {
+				next, _ := utf8.DecodeLastRuneInString(tokens[j+1])
+				last, _ := utf8.DecodeLastRuneInString(tokens[j+2])
+				terms.Phrase = tok + string(next) + string(last)
+			}
+		}
+	}
+	if len(bigrams) == 0 {
+		return cjkTerms{}, fmt.Errorf("no Japanese text in the sampled logs")
+	}
+
+	terms.Char = mostFrequent(chars)
+	terms.Bigram = mostFrequent(bigrams)
+	if terms.Phrase == "" {
+		terms.Phrase = terms.Bigram
+	}
+	return terms, nil
+}
+
+// mostFrequent returns the key with the highest count, breaking ties by key so
+// the same sample always gives the same term.
+func mostFrequent(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys[0]
+}
+
+// cjkCases search Japanese text with the content_cjk bigram index (FTS-CJK),
+// with ILIKE (Partial), and with the english configuration (FTS), which
+// indexes each run of Japanese as one token and so misses substrings.
+func cjkCases(terms cjkTerms) []BenchmarkCase {
+	return []BenchmarkCase{
+		{Name: "FTS-CJK Char Limit", SearchType: "FTS-CJK", Term: terms.Char, Limit: 100, Desc: "Most frequent Japanese character, Limit 100"},
+		{Name: "FTS-CJK Bigram Limit", SearchType: "FTS-CJK", Term: terms.Bigram, Limit: 100, Desc: "Most frequent Japanese bigram, Limit 100"},
+		{Name: "FTS-CJK Phrase (Few)", SearchType: "FTS-CJK", Term: terms.Phrase, Limit: 100, Desc: "4 Japanese characters from one document"},
+		{Name: "Partial CJK Bigram Limit", SearchType: "Partial", Term: terms.Bigram, Limit: 100, Desc: "Most frequent Japanese bigram, Limit 100"},
+		{Name: "Partial CJK Phrase (Few)", SearchType: "Partial", Term: terms.Phrase, Limit: 100, Desc: "4 Japanese characters from one document"},
+		{Name: "FTS CJK Phrase (Few)", SearchType: "FTS", Term: terms.Phrase, Limit: 100, Desc: "4 Japanese characters, english configuration"},
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// caseKey identifies the same benchmark case across two result files.
+type caseKey struct {
+	Name        string
+	Dataset     int64
+	ContentSize string
+	IndexConfig string
+}
+
+func keyOf(r Result) caseKey {
+	return caseKey{Name: r.Case.Name, Dataset: r.Dataset, ContentSize: r.ContentSize, IndexConfig: r.IndexConfig}
+}
+
+type comparison struct {
+	Key       caseKey
+	Baseline  *Result
+	Candidate *Result
+	Delta     time.Duration
+	DeltaPct  float64
+	Status    string // "ok", "regression", "improved", "missing", "new", "error"
+}
+
+// runCompare implements `benchmark compare`. It exits non-zero when any case
+// regressed beyond the threshold.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	baselinePath := fs.String("baseline", "", "Baseline results file (JSON, from -format json)")
+	candidatePath := fs.String("candidate", "", "Candidate results file (JSON, from -format json)")
+	threshold := fs.Float64("threshold", 10, "Regression threshold in percent")
+	minDelta := fs.Duration("min-delta", time.Millisecond, "Ignore slowdowns smaller than this, regardless of percentage")
+	metric := fs.String("metric", "p50", "Statistic to compare: min, p50, p95, p99, max or mean")
+	fs.Parse(args)
+
+	if *baselinePath == "" || *candidatePath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if _, ok := metricValue(Stats{}, *metric); !ok {
+		log.Fatalf("Unknown -metric %q", *metric)
+	}
+
+	baseline, err := readReport(*baselinePath)
+	if err != nil {
+		log.Fatalf("Failed to read baseline: %v", err)
+	}
+	candidate, err := readReport(*candidatePath)
+	if err != nil {
+		log.Fatalf("Failed to read candidate: %v", err)
+	}
+
+	comparisons := compareReports(baseline, candidate, *metric, *threshold, *minDelta)
+	writeComparison(os.Stdout, baseline, candidate, comparisons, *metric)
+
+	regressions := 0
+	for _, c := range comparisons {
+		if c.Status == "regression" || c.Status == "error" {
+			regressions++
+		}
+	}
+	if regressions > 0 {
+		log.Printf("%d case(s) regressed by more than %.1f%% (%s)", regressions, *threshold, *metric)
+		os.Exit(1)
+	}
+	log.Printf("No regressions beyond %.1f%% (%s)", *threshold, *metric)
+}
+
+func readReport(path string) (Report, error) {
+	var report Report
+	f, err := os.Open(path)
+	if err != nil {
+		return report, err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&report); err != nil {
+		return report, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+func compareReports(baseline, candidate Report, metric string, threshold float64, minDelta time.Duration) []comparison {
+	candidates := make(map[caseKey]*Result, len(candidate.Results))
+	for i := range candidate.Results {
+		candidates[keyOf(candidate.Results[i])] = &candidate.Results[i]
+	}
+
+	var out []comparison
+	matched := make(map[caseKey]bool)
+	for i := range baseline.Results {
+		base := &baseline.Results[i]
+		key := keyOf(*base)
+		cmp := comparison{Key: key, Baseline: base}
+
+		cand, ok := candidates[key]
+		if !ok {
+			cmp.Status = "missing"
+			out = append(out, cmp)
+			continue
+		}
+		matched[key] = true
+		cmp.Candidate = cand
+
+		switch {
+		case cand.Error != "" && base.Error == "":
+			cmp.Status = "error"
+		case cand.Error != "" || base.Error != "":
+			cmp.Status = "ok"
+		default:
+			b, _ := metricValue(base.Stats, metric)
+			c, _ := metricValue(cand.Stats, metric)
+			cmp.Delta = c - b
+			if b > 0 {
+				cmp.DeltaPct = float64(cmp.Delta) / float64(b) * 100
+			}
+
+			switch {
+			case cmp.DeltaPct > threshold && cmp.Delta > minDelta:
+				cmp.Status = "regression"
+			case cmp.DeltaPct < -threshold && -cmp.Delta > minDelta:
+				cmp.Status = "improved"
+			default:
+				cmp.Status = "ok"
+			}
+		}
+		out = append(out, cmp)
+	}
+
+	for i := range candidate.Results {
+		cand := &candidate.Results[i]
+		if key := keyOf(*cand); !matched[key] {
+			out = append(out, comparison{Key: key, Candidate: cand, Status: "new"})
+		}
+	}
+
+	return out
+}
+
+func metricValue(st Stats, metric string) (time.Duration, bool) {
+	switch metric {
+	case "min":
+		return st.Min, true
+	case "p50":
+		return st.P50, true
+	case "p95":
+		return st.P95, true
+	case "p99":
+		return st.P99, true
+	case "max":
+		return st.Max, true
+	case "mean":
+		return st.Mean, true
+	}
+	return 0, false
+}
+
+func writeComparison(out io.Writer, baseline, candidate Report, comparisons []comparison, metric string) {
+	fmt.Fprintf(out, "Baseline:  %s (%s, PostgreSQL %s)\n", baseline.Metadata.GitCommit, baseline.Metadata.Timestamp.Format(time.RFC3339), baseline.Metadata.PostgresVersion)
+	fmt.Fprintf(out, "Candidate: %s (%s, PostgreSQL %s)\n\n", candidate.Metadata.GitCommit, candidate.Metadata.Timestamp.Format(time.RFC3339), candidate.Metadata.PostgresVersion)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Dataset\tRecordSize\tIndexes\tCase\tBaseline %s\tCandidate %s\tDelta\tDelta %%\tStatus\n", metric, metric)
+	for _, c := range comparisons {
+		base, cand := "-", "-"
+		if c.Baseline != nil {
+			base = resultCell(*c.Baseline, metric)
+		}
+		if c.Candidate != nil {
+			cand = resultCell(*c.Candidate, metric)
+		}
+
+		delta, pct := "-", "-"
+		if c.Baseline != nil && c.Candidate != nil && c.Baseline.Error == "" && c.Candidate.Error == "" {
+			delta = roundDuration(c.Delta).String()
+			pct = fmt.Sprintf("%+.1f%%", c.DeltaPct)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Key.Dataset, c.Key.ContentSize, indexConfigLabel(c.Key.IndexConfig), c.Key.Name, base, cand, delta, pct, c.Status)
+	}
+	w.Flush()
+}
+
+func resultCell(r Result, metric string) string {
+	if r.Error != "" {
+		return "ERROR"
+	}
+	v, _ := metricValue(r.Stats, metric)
+	return roundDuration(v).String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"log-project/database"
+	"log-project/internal/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Plan is the EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) output of a case along
+// with the figures we care about when deciding between indexes.
+type Plan struct {
+	Raw              json.RawMessage `json:"raw"`
+	Access           string          `json:"access"`       // e.g. "Bitmap Index Scan on idx_logs_content_tsv" or "Seq Scan on logs"
+	Indexes          []string        `json:"indexes"`      // Every index touched by the plan
+	RowsScanned      int64           `json:"rows_scanned"` // Rows read by scan nodes, including those removed by filters/rechecks
+	SharedHitBlocks  int64           `json:"shared_hit_blocks"`
+	SharedReadBlocks int64           `json:"shared_read_blocks"`
+	PlanningTime     float64         `json:"planning_time_ms"`
+	ExecutionTime    float64         `json:"execution_time_ms"`
+}
+
+type explainOutput struct {
+	Plan          planNode `json:"Plan"`
+	PlanningTime  float64  `json:"Planning Time"`
+	ExecutionTime float64  `json:"Execution Time"`
+}
+
+type planNode struct {
+	NodeType                  string     `json:"Node Type"`
+	RelationName              string     `json:"Relation Name"`
+	IndexName                 string     `json:"Index Name"`
+	ActualRows                float64    `json:"Actual Rows"`
+	ActualLoops               float64    `json:"Actual Loops"`
+	RowsRemovedByFilter       float64    `json:"Rows Removed by Filter"`
+	RowsRemovedByIndexRecheck float64    `json:"Rows Removed by Index Recheck"`
+	SharedHitBlocks           int64      `json:"Shared Hit Blocks"`
+	SharedReadBlocks          int64      `json:"Shared Read Blocks"`
+	Plans                     []planNode `json:"Plans"`
+}
+
+// explainCase re-runs c under EXPLAIN ANALYZE using the exact SQL and
+// arguments of its sqlc query.
+func explainCase(ctx context.Context, conn *pgx.Conn, c BenchmarkCase) (*Plan, error) {
+	run := caseQuery(ctx, c)
+	captured, err := database.CaptureQuery(func(q *db.Queries) error {
+		_, err := run(q)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []byte
+	err = conn.QueryRow(ctx, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+captured.SQL, captured.Args...).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	return parsePlan(raw)
+}
+
+func parsePlan(raw []byte) (*Plan, error) {
+	var out []explainOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty plan")
+	}
+
+	root := out[0].Plan
+	p := &Plan{
+		Raw:              json.RawMessage(raw),
+		SharedHitBlocks:  root.SharedHitBlocks,
+		SharedReadBlocks: root.SharedReadBlocks,
+		PlanningTime:     out[0].PlanningTime,
+		ExecutionTime:    out[0].ExecutionTime,
+	}
+
+	var access []string
+	seen := make(map[string]bool)
+	walkPlan(root, func(n planNode) {
+		loops := n.ActualLoops
+		if loops == 0 {
+			loops = 1
+		}
+
+		switch {
+		case n.IndexName != "":
+			label := n.NodeType + " on " + n.IndexName
+			if !seen[label] {
+				seen[label] = true
+				access = append(access, label)
+				p.Indexes = append(p.Indexes, n.IndexName)
+			}
+		case n.NodeType == "Seq Scan":
+			label := n.NodeType + " on " + n.RelationName
+			if !seen[label] {
+				seen[label] = true
+				access = append(access, label)
+			}
+		}
+
+		// Bitmap Index Scans only produce TIDs; their heap rows are counted
+		// on the Bitmap Heap Scan above them.
+		if n.RelationName != "" && strings.HasSuffix(n.NodeType, "Scan") {
+			p.RowsScanned += int64((n.ActualRows + n.RowsRemovedByFilter + n.RowsRemovedByIndexRecheck) * loops)
+		}
+	})
+	p.Access = strings.Join(access, ", ")
+
+	return p, nil
+}
+
+func walkPlan(n planNode, fn func(planNode)) {
+	fn(n)
+	for _, child := range n.Plans {
+		walkPlan(child, fn)
+	}
+}
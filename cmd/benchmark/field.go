@@ -0,0 +1,23 @@
+package main
+
+// fieldCases search one JSON field with field= (Field set) next to the same
+// term over the whole document. "error" is both a status value and part of
+// keys such as error_code, which unscoped search matches as well.
+func fieldCases(contentSize string) []BenchmarkCase {
+	cases := []BenchmarkCase{
+		{Name: "FTS Field Status Limit", SearchType: "FTS", Term: "error", Field: "status", Limit: 100, Desc: "status matching error, Limit 100"},
+		{Name: "FTS Unscoped Status Limit", SearchType: "FTS", Term: "error", Limit: 100, Desc: "error anywhere in content, Limit 100"},
+		{Name: "Partial Field Status Limit", SearchType: "Partial", Term: "error", Field: "status", Limit: 100, Desc: "status containing error, Limit 100"},
+		{Name: "Partial Unscoped Status Limit", SearchType: "Partial", Term: "error", Limit: 100, Desc: "error anywhere in content, Limit 100"},
+	}
+	// Only large content has the nested_obj_N objects
+	if contentSize == "large" {
+		cases = append(cases,
+			BenchmarkCase{Name: "FTS Field Nested Wildcard Limit", SearchType: "FTS", Term: "nested", Field: "nested_obj_*.nested_field_0", Limit: 100,
+				Desc: "nested_field_0 under any nested_obj_N, Limit 100"},
+			BenchmarkCase{Name: "Partial Field Nested Wildcard Limit", SearchType: "Partial", Term: "nested_value_1", Field: "nested_obj_*.nested_field_0", Limit: 100,
+				Desc: "nested_field_0 under any nested_obj_N, Limit 100"},
+		)
+	}
+	return cases
+}
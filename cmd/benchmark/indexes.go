@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// managedIndexes are the content indexes the harness may drop and recreate.
+// B-tree/BRIN indexes on the other columns are never touched.
+// idx_logs_content_simple is not part of the schema (migration 00007 drops
+// it); only the "simple" config creates it, to measure what it would buy.
+var managedIndexes = []struct {
+	Name string
+	Def  string
+}{
+	{"idx_logs_content_gin", "CREATE INDEX idx_logs_content_gin ON logs USING GIN (content)"},
+	{"idx_logs_content_path_ops", "CREATE INDEX idx_logs_content_path_ops ON logs USING GIN (content jsonb_path_ops)"},
+	{"idx_logs_content_fts", "CREATE INDEX idx_logs_content_fts ON logs USING GIN (to_tsvector('english', content::text))"},
+	{"idx_logs_content_tsv", "CREATE INDEX idx_logs_content_tsv ON logs USING GIN (content_tsv)"},
+	{"idx_logs_content_trgm", "CREATE INDEX idx_logs_content_trgm ON logs USING GIN ((content::text) gin_trgm_ops)"},
+	{"idx_logs_content_simple", "CREATE INDEX idx_logs_content_simple ON logs USING GIN (to_tsvector('simple', content::text))"},
+	{"idx_logs_content_cjk_tsv", "CREATE INDEX idx_logs_content_cjk_tsv ON logs USING GIN (content_cjk_tsv)"},
+}
+
+// indexConfigs maps a configuration name to the managed indexes it keeps.
+var indexConfigs = map[string][]string{
+	"none":           {},
+	"fts":            {"idx_logs_content_fts", "idx_logs_content_tsv"},
+	"trgm":           {"idx_logs_content_trgm"},
+	"both":           {"idx_logs_content_fts", "idx_logs_content_tsv", "idx_logs_content_trgm"},
+	"jsonb_path_ops": {"idx_logs_content_path_ops"},
+	"simple":         {"idx_logs_content_simple"},
+	"cjk":            {"idx_logs_content_cjk_tsv"},
+	"default":        {"idx_logs_content_gin", "idx_logs_content_fts", "idx_logs_content_tsv", "idx_logs_content_trgm", "idx_logs_content_cjk_tsv"},
+}
+
+// IndexConfig is a named set of managed indexes to benchmark with.
+type IndexConfig struct {
+	Name    string
+	Indexes []string
+}
+
+// indexSnapshot is the logs table's index definitions before the harness ran.
+type indexSnapshot map[string]string
+
+func parseIndexConfigs(s string) ([]IndexConfig, error) {
+	var configs []IndexConfig
+	for _, name := range splitList(s) {
+		indexes, ok := indexConfigs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown index config %q (want none, fts, trgm, both, jsonb_path_ops, simple, cjk or default)", name)
+		}
+		configs = append(configs, IndexConfig{Name: name, Indexes: indexes})
+	}
+	return configs, nil
+}
+
+func snapshotIndexes(ctx context.Context, conn *pgx.Conn) (indexSnapshot, error) {
+	rows, err := conn.Query(ctx, "SELECT indexname, indexdef FROM pg_indexes WHERE schemaname = current_schema() AND tablename = 'logs'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snap := make(indexSnapshot)
+	for rows.Next() {
+		var name, def string
+		if err := rows.Scan(&name, &def); err != nil {
+			return nil, err
+		}
+		snap[name] = def
+	}
+	return snap, rows.Err()
+}
+
+// applyIndexConfig drops managed indexes not in cfg, creates the missing
+// ones, and refreshes planner statistics.
+func applyIndexConfig(ctx context.Context, conn *pgx.Conn, cfg IndexConfig) error {
+	current, err := snapshotIndexes(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	want := make(map[string]bool, len(cfg.Indexes))
+	for _, name := range cfg.Indexes {
+		want[name] = true
+	}
+
+	for _, idx := range managedIndexes {
+		_, exists := current[idx.Name]
+		switch {
+		case exists && !want[idx.Name]:
+			if _, err := conn.Exec(ctx, "DROP INDEX IF EXISTS "+idx.Name); err != nil {
+				return fmt.Errorf("failed to drop %s: %w", idx.Name, err)
+			}
+		case !exists && want[idx.Name]:
+			log.Printf("Creating %s...", idx.Name)
+			start := time.Now()
+			if _, err := conn.Exec(ctx, idx.Def); err != nil {
+				return fmt.Errorf("failed to create %s: %w", idx.Name, err)
+			}
+			log.Printf("Created %s in %s", idx.Name, time.Since(start))
+		}
+	}
+
+	if _, err := conn.Exec(ctx, "ANALYZE logs"); err != nil {
+		return fmt.Errorf("failed to analyze logs: %w", err)
+	}
+	return nil
+}
+
+// restoreIndexes puts the managed indexes back the way snapshotIndexes found
+// them, using the original definitions.
+func restoreIndexes(ctx context.Context, conn *pgx.Conn, snap indexSnapshot) error {
+	current, err := snapshotIndexes(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	for _, idx := range managedIndexes {
+		_, now := current[idx.Name]
+		def, before := snap[idx.Name]
+		switch {
+		case now && !before:
+			if _, err := conn.Exec(ctx, "DROP INDEX IF EXISTS "+idx.Name); err != nil {
+				return fmt.Errorf("failed to drop %s: %w", idx.Name, err)
+			}
+		case !now && before:
+			log.Printf("Restoring %s...", idx.Name)
+			if _, err := conn.Exec(ctx, def); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", idx.Name, err)
+			}
+		}
+	}
+
+	_, err = conn.Exec(ctx, "ANALYZE logs")
+	return err
+}
+
+// restoreIndexesAfterRun restores snap once the run is over. It ignores the
+// run's context, which an interrupt has cancelled, and reconnects when a
+// cancelled query left conn closed.
+func restoreIndexesAfterRun(connStr string, conn *pgx.Conn, snap indexSnapshot) error {
+	ctx := context.Background()
+	if conn.IsClosed() {
+		fresh, err := pgx.Connect(ctx, connStr)
+		if err != nil {
+			return fmt.Errorf("failed to reconnect: %w", err)
+		}
+		defer fresh.Close(ctx)
+		conn = fresh
+	}
+	return restoreIndexes(ctx, conn, snap)
+}
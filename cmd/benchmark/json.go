@@ -0,0 +1,28 @@
+package main
+
+// jsonCases filter on content keys with json_eq, json_has, json_range and
+// jsonpath. Equalities and key existence are answered by idx_logs_content_gin
+// (jsonb_path_ops only covers equalities); numeric ranges are not indexable
+// and are checked row by row, unless an equality in the same case narrows
+// the rows first.
+func jsonCases(count int64, contentSize string) []BenchmarkCase {
+	cases := []BenchmarkCase{
+		{Name: "JSON Eq Status Limit", SearchType: "JSON", JSONEq: []string{"status:error"}, Limit: 100, Desc: "status = error (1 in 7), Limit 100"},
+		{Name: "JSON Eq Status No Limit", SearchType: "JSON", JSONEq: []string{"status:error"}, Limit: int32(count), Desc: "status = error (1 in 7), All rows"},
+		{Name: "JSON Eq Device (Few)", SearchType: "JSON", JSONEq: []string{"device_id:device_42"}, Limit: 100, Desc: "device_id = device_42 (1 in 10,000)"},
+		{Name: "JSON Range Duration (Few)", SearchType: "JSON", JSONRange: []string{"duration>=5000"}, Limit: 100, Desc: "duration >= 5000 (1 in 50), not indexable"},
+		{Name: "JSON Eq+Range (Few)", SearchType: "JSON", JSONEq: []string{"status:error"}, JSONRange: []string{"duration>4000"}, Limit: 100,
+			Desc: "status = error and duration > 4000"},
+		{Name: "JSON Path Eq+Range (Few)", SearchType: "JSON", JSONPath: `$.status == "error" && $.duration > 4000`, Limit: 100,
+			Desc: "The same filter as one raw jsonpath predicate"},
+	}
+	// Small content has no error_code, so the existence check matches nothing
+	if contentSize != "small" {
+		cases = append(cases,
+			BenchmarkCase{Name: "JSON Has Error Code Limit", SearchType: "JSON", JSONHas: []string{"error_code"}, Limit: 100, Desc: "error_code exists (every row), Limit 100"},
+			BenchmarkCase{Name: "JSON Eq Error Code+Region Limit", SearchType: "JSON", JSONEq: []string{`error_code:"500"`, "region:eu-west-1"}, Limit: 100,
+				Desc: "error_code = 500 and region = eu-west-1 (1 in 50), Limit 100"},
+		)
+	}
+	return cases
+}
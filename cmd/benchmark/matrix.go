@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"log-project/internal/db"
+	"log-project/seeder"
+	"log-project/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// matrixEntry is one Dataset x RecordSize combination to seed and benchmark.
+// ContentSize is the label used in results: the size itself, or
+// "profile:<name>" for a content profile, with an "@<n>B" suffix when rows are
+// padded to n bytes.
+type matrixEntry struct {
+	Dataset     int
+	ContentSize string
+	Profile     *utils.Profile
+	RowBytes    int
+
+	size string // Built-in content size passed to the seeder
+}
+
+// parseMatrix expands the -datasets, -contents and -profile flags into the
+// ordered list of combinations to run. An empty datasets list means "use the
+// current table".
+func parseMatrix(datasets, contents, profiles string) ([]matrixEntry, error) {
+	if strings.TrimSpace(datasets) == "" {
+		return nil, nil
+	}
+
+	var sizes []int
+	for _, s := range splitList(datasets) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid dataset size %q", s)
+		}
+		sizes = append(sizes, n)
+	}
+
+	contentSizes := splitList(contents)
+	for _, c := range contentSizes {
+		switch c {
+		case "small", "medium", "large":
+		default:
+			return nil, fmt.Errorf("invalid content size %q (want small, medium or large)", c)
+		}
+	}
+	var loaded []*utils.Profile
+	for _, path := range splitList(profiles) {
+		p, err := utils.LoadProfile(path)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, p)
+	}
+	if len(contentSizes) == 0 && len(loaded) == 0 {
+		return nil, fmt.Errorf("at least one content size or profile is required")
+	}
+
+	var matrix []matrixEntry
+	for _, n := range sizes {
+		for _, c := range contentSizes {
+			matrix = append(matrix, matrixEntry{Dataset: n, ContentSize: c, size: c})
+		}
+		for _, p := range loaded {
+			matrix = append(matrix, matrixEntry{Dataset: n, ContentSize: "profile:" + p.Name, Profile: p})
+		}
+	}
+	return matrix, nil
+}
+
+// seedDataset truncates the logs table and fills it through the same COPY path
+// used by Handler.InitializeData, then refreshes planner statistics. opts
+// carries the seed and pipeline settings; the size comes from m. A fixed seed
+// makes every run of the same matrix entry load identical rows.
+func seedDataset(ctx context.Context, pool *pgxpool.Pool, m matrixEntry, opts seeder.Options) error {
+	queries := db.New(pool)
+	if err := queries.TruncateLogs(ctx); err != nil {
+		return fmt.Errorf("failed to truncate logs: %w", err)
+	}
+
+	opts.RecordCount = m.Dataset
+	m.seederOptions(&opts)
+	log.Printf("Seeding %d records with %s content size (seed %d)...", m.Dataset, m.ContentSize, opts.Seed)
+	result, err := seeder.Run(ctx, pool, opts, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("Seeded %d records in %s (%.2f records/sec)", result.Inserted, result.Duration, result.RecordsPerSecond())
+	logStages(result)
+
+	if _, err := pool.Exec(ctx, "ANALYZE logs"); err != nil {
+		return fmt.Errorf("failed to analyze logs: %w", err)
+	}
+	return nil
+}
+
+// withRowBytes pads every entry's rows to rowBytes bytes of JSON.
+func withRowBytes(matrix []matrixEntry, rowBytes int) {
+	if rowBytes <= 0 {
+		return
+	}
+	for i := range matrix {
+		matrix[i].RowBytes = rowBytes
+		matrix[i].ContentSize = fmt.Sprintf("%s@%dB", matrix[i].ContentSize, rowBytes)
+	}
+}
+
+// seederOptions fills in the size and content shape of m.
+func (m matrixEntry) seederOptions(opts *seeder.Options) {
+	opts.RecordCount = m.Dataset
+	opts.ContentSize = m.size
+	opts.Profile = m.Profile
+	opts.TargetRowBytes = m.RowBytes
+}
+
+// logStages reports per-stage pipeline throughput so it is obvious whether
+// generation or COPY limited the seeding rate.
+func logStages(result seeder.Result) {
+	log.Printf("  generate: %d workers, %.0f records/sec, %.0f%% busy",
+		result.Generate.Workers, result.Generate.RecordsPerSecond(), result.Generate.Utilization(result.Duration)*100)
+	log.Printf("  write:    %d workers, %.0f records/sec, %.0f%% busy",
+		result.Write.Workers, result.Write.RecordsPerSecond(), result.Write.Utilization(result.Duration)*100)
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// matrixDimensions returns the distinct dataset and content sizes, in order.
+func matrixDimensions(matrix []matrixEntry) ([]int64, []string) {
+	var datasets []int64
+	var contents []string
+	seenDataset := make(map[int]bool)
+	seenContent := make(map[string]bool)
+	for _, m := range matrix {
+		if !seenDataset[m.Dataset] {
+			seenDataset[m.Dataset] = true
+			datasets = append(datasets, int64(m.Dataset))
+		}
+		if !seenContent[m.ContentSize] {
+			seenContent[m.ContentSize] = true
+			contents = append(contents, m.ContentSize)
+		}
+	}
+	return datasets, contents
+}
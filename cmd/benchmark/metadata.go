@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Report is the full result set of one benchmark run, as written by -format.
+type Report struct {
+	Metadata Metadata        `json:"metadata"`
+	Results  []Result        `json:"results"`
+	Storage  []storageResult `json:"storage"`
+}
+
+// Metadata records everything needed to reproduce or compare a run.
+type Metadata struct {
+	Timestamp        time.Time         `json:"timestamp"`
+	GitCommit        string            `json:"git_commit"`
+	PostgresVersion  string            `json:"postgres_version"`
+	Datasets         []int64           `json:"datasets"`
+	ContentSizes     []string          `json:"content_sizes"`
+	IndexConfigs     []string          `json:"index_configs,omitempty"`
+	Settings         Settings          `json:"settings"`
+	PostgresSettings map[string]string `json:"postgres_settings"`
+}
+
+// Settings are the benchmark options the run was started with.
+type Settings struct {
+	Iterations int   `json:"iterations"`
+	Explain    bool  `json:"explain"`
+	Seed       int64 `json:"seed"`
+}
+
+// postgresSettings are the server parameters that most affect search latency.
+var postgresSettings = []string{
+	"shared_buffers",
+	"work_mem",
+	"effective_cache_size",
+	"random_page_cost",
+	"max_parallel_workers_per_gather",
+	"jit",
+	"gin_fuzzy_search_limit",
+}
+
+func collectMetadata(ctx context.Context, conn *pgx.Conn, opts suiteOptions) Metadata {
+	md := Metadata{
+		Timestamp: time.Now().UTC(),
+		GitCommit: gitCommit(),
+		Settings: Settings{
+			Iterations: opts.Iterations,
+			Explain:    opts.Explain,
+			Seed:       opts.Seed,
+		},
+		PostgresSettings: make(map[string]string),
+	}
+
+	if err := conn.QueryRow(ctx, "SHOW server_version").Scan(&md.PostgresVersion); err != nil {
+		log.Printf("Warning: Failed to read Postgres version: %v", err)
+	}
+
+	rows, err := conn.Query(ctx, "SELECT name, setting || COALESCE(unit, '') FROM pg_settings WHERE name = ANY($1)", postgresSettings)
+	if err != nil {
+		log.Printf("Warning: Failed to read Postgres settings: %v", err)
+		return md
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			log.Printf("Warning: Failed to read Postgres settings: %v", err)
+			break
+		}
+		md.PostgresSettings[name] = value
+	}
+
+	return md
+}
+
+// gitCommit returns the current commit, preferring the working tree (so
+// `go run` picks up uncommitted changes as "-dirty") over build info.
+func gitCommit() string {
+	if out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output(); err == nil {
+		commit := strings.TrimSpace(string(out))
+		if status, err := exec.Command("git", "status", "--porcelain").Output(); err == nil && len(strings.TrimSpace(string(status))) > 0 {
+			commit += "-dirty"
+		}
+		return commit
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+	return "unknown"
+}
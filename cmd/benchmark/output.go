@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func validFormat(format string) bool {
+	switch format {
+	case "table", "json", "csv", "markdown":
+		return true
+	}
+	return false
+}
+
+func writeReport(w io.Writer, format string, report Report) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "csv":
+		return writeCSV(w, report)
+	case "markdown":
+		return writeMarkdown(w, report)
+	default:
+		return writeTable(w, report)
+	}
+}
+
+func writeTable(out io.Writer, report Report) error {
+	explain := report.Metadata.Settings.Explain
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	header := "Dataset\tRecordSize\tIndexes\tType\tCase\tLimit\tCold\tMin\tP50\tP95\tP99\tMax\tMean\tStdDev\tRows\tDescription"
+	if explain {
+		header += "\tAccess\tRows Scanned\tShared Hit\tShared Read\tPlanning\tExecution"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, res := range report.Results {
+		c := res.Case
+		if res.Error != "" {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t-\tERROR\t\t\t\t\t\t\t\t-\t%s\n", res.Dataset, res.ContentSize, indexConfigLabel(res.IndexConfig), c.SearchType, c.Name, res.Error)
+			continue
+		}
+		st := res.Stats
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%d\t%s",
+			res.Dataset, res.ContentSize, indexConfigLabel(res.IndexConfig), c.SearchType, c.Name, limitLabel(res),
+			roundDuration(st.Cold), roundDuration(st.Min), roundDuration(st.P50), roundDuration(st.P95),
+			roundDuration(st.P99), roundDuration(st.Max), roundDuration(st.Mean), roundDuration(st.StdDev),
+			res.RowsFound, c.Desc)
+		if explain {
+			if p := res.Plan; p != nil {
+				fmt.Fprintf(w, "\t%s\t%d\t%d\t%d\t%.3fms\t%.3fms", p.Access, p.RowsScanned, p.SharedHitBlocks, p.SharedReadBlocks, p.PlanningTime, p.ExecutionTime)
+			} else {
+				fmt.Fprint(w, "\t-\t-\t-\t-\t-\t-")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "\nDataset\tRecordSize\tIndexes\tExpression Index (idx_logs_content_fts)\tStored Index (idx_logs_content_tsv)\tStored Column (content_tsv)")
+	for _, s := range report.Storage {
+		if s.Error != "" {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", s.Dataset, s.ContentSize, indexConfigLabel(s.IndexConfig),
+			formatBytes(s.Stats.ExpressionIndexBytes),
+			formatBytes(s.Stats.StoredIndexBytes),
+			formatBytes(s.Stats.StoredColumnBytes))
+	}
+	return w.Flush()
+}
+
+// writeCSV emits one row per result. Metadata is written as leading "#"
+// comment lines, which encoding/csv readers can skip via Reader.Comment.
+func writeCSV(out io.Writer, report Report) error {
+	md := report.Metadata
+	fmt.Fprintf(out, "# timestamp: %s\n", md.Timestamp.Format("2006-01-02T15:04:05Z07:00"))
+	fmt.Fprintf(out, "# git_commit: %s\n", md.GitCommit)
+	fmt.Fprintf(out, "# postgres_version: %s\n", md.PostgresVersion)
+	fmt.Fprintf(out, "# settings: %s\n", settingsSummary(md))
+
+	w := csv.NewWriter(out)
+	w.Write([]string{
+		"dataset", "content_size", "index_config", "search_type", "case", "limit", "rows",
+		"cold_ms", "min_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms", "mean_ms", "stddev_ms", "samples",
+		"access", "rows_scanned", "shared_hit_blocks", "shared_read_blocks", "planning_ms", "execution_ms",
+		"error",
+	})
+	for _, res := range report.Results {
+		st := res.Stats
+		record := []string{
+			strconv.FormatInt(res.Dataset, 10), res.ContentSize, res.IndexConfig, res.Case.SearchType, res.Case.Name,
+			strconv.Itoa(int(res.Case.Limit)), strconv.Itoa(res.RowsFound),
+			msString(durationMs(st.Cold)), msString(durationMs(st.Min)), msString(durationMs(st.P50)),
+			msString(durationMs(st.P95)), msString(durationMs(st.P99)), msString(durationMs(st.Max)),
+			msString(durationMs(st.Mean)), msString(durationMs(st.StdDev)), strconv.Itoa(st.Samples),
+		}
+		if p := res.Plan; p != nil {
+			record = append(record, p.Access, strconv.FormatInt(p.RowsScanned, 10),
+				strconv.FormatInt(p.SharedHitBlocks, 10), strconv.FormatInt(p.SharedReadBlocks, 10),
+				msString(p.PlanningTime), msString(p.ExecutionTime))
+		} else {
+			record = append(record, "", "", "", "", "", "")
+		}
+		record = append(record, res.Error)
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeMarkdown renders the layout used in BENCHMARK_SUMMARY.md, with the
+// dataset and record size only shown on the first row of each group.
+func writeMarkdown(out io.Writer, report Report) error {
+	md := report.Metadata
+	fmt.Fprintf(out, "*   **Generated**: %s\n", md.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(out, "*   **Git commit**: `%s`\n", md.GitCommit)
+	fmt.Fprintf(out, "*   **PostgreSQL**: %s\n", md.PostgresVersion)
+	fmt.Fprintf(out, "*   **Settings**: %s\n\n", settingsSummary(md))
+
+	fmt.Fprintln(out, "| Dataset Size | Record Size | Test Case | Duration (ms) |")
+	fmt.Fprintln(out, "| :--- | :--- | :--- | :--- |")
+
+	var lastDataset int64 = -1
+	lastContent := ""
+	for _, res := range report.Results {
+		datasetCell, contentCell := "", ""
+		if res.Dataset != lastDataset {
+			datasetCell = "**" + formatThousands(res.Dataset) + "**"
+			contentCell = titleCase(res.ContentSize)
+		} else if res.ContentSize != lastContent {
+			contentCell = titleCase(res.ContentSize)
+		}
+		lastDataset, lastContent = res.Dataset, res.ContentSize
+
+		duration := fmt.Sprintf("%.2f", durationMs(res.Stats.P50))
+		if res.Error != "" {
+			duration = "*Error*"
+		}
+		name := res.Case.Name
+		if res.IndexConfig != "" {
+			name += " [" + res.IndexConfig + "]"
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s |\n", datasetCell, contentCell, name, duration)
+	}
+
+	fmt.Fprintf(out, "\n*Note: Durations are the p50 of %d warm iterations. %s*\n", md.Settings.Iterations, markdownNote)
+	return nil
+}
+
+const markdownNote = `"FTS" stands for Full-Text Search (using GIN index). "Partial" refers to partial match searches (e.g., ` + "`LIKE '%term%'`" + `).`
+
+func limitLabel(res Result) string {
+	if int64(res.Case.Limit) == res.Dataset {
+		return "ALL"
+	}
+	return strconv.Itoa(int(res.Case.Limit))
+}
+
+func indexConfigLabel(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+func settingsSummary(md Metadata) string {
+	parts := []string{
+		fmt.Sprintf("iterations=%d", md.Settings.Iterations),
+		fmt.Sprintf("explain=%t", md.Settings.Explain),
+		fmt.Sprintf("seed=%d", md.Settings.Seed),
+	}
+	if len(md.IndexConfigs) > 0 {
+		parts = append(parts, "index_configs="+strings.Join(md.IndexConfigs, "/"))
+	}
+	names := make([]string, 0, len(md.PostgresSettings))
+	for name := range md.PostgresSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+md.PostgresSettings[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func msString(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', 3, 64)
+}
+
+func formatThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pageDepths are how far into the table (as a fraction of its rows) the
+// pagination cases start reading.
+var pageDepths = []float64{0.01, 0.5, 0.9}
+
+// pageLimit is the page size of the pagination cases.
+const pageLimit = 100
+
+// pagePosition is a page deep into the table, reachable either with OFFSET or
+// with a keyset cursor on the row just before it.
+type pagePosition struct {
+	Depth     float64
+	Offset    int32
+	CreatedAt pgtype.Timestamptz
+	ID        pgtype.UUID
+}
+
+// discoverPages finds the keyset cursor matching each depth in pageDepths.
+func discoverPages(ctx context.Context, conn *pgx.Conn, count int64) []pagePosition {
+	var pages []pagePosition
+	for _, depth := range pageDepths {
+		offset := int32(float64(count) * depth)
+		if offset < 1 {
+			continue
+		}
+
+		p := pagePosition{Depth: depth, Offset: offset}
+		err := conn.QueryRow(ctx,
+			"SELECT created_at, id FROM logs ORDER BY created_at DESC, id DESC OFFSET $1 LIMIT 1",
+			offset-1,
+		).Scan(&p.CreatedAt, &p.ID)
+		if err != nil {
+			log.Printf("Warning: Failed to find the cursor at offset %d: %v", offset, err)
+			continue
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// paginationCases fetches the same pages with OFFSET and with a keyset cursor,
+// as /api/logs does for page=N and cursor=... respectively.
+func paginationCases(pages []pagePosition) []BenchmarkCase {
+	var cases []BenchmarkCase
+	for _, p := range pages {
+		label := fmt.Sprintf("%g%%", p.Depth*100)
+		cases = append(cases,
+			BenchmarkCase{Name: "Page Offset " + label, SearchType: "Offset", Limit: pageLimit, Offset: p.Offset,
+				Desc: fmt.Sprintf("LIMIT/OFFSET at row %d", p.Offset)},
+			BenchmarkCase{Name: "Page Keyset " + label, SearchType: "Keyset", Limit: pageLimit, Offset: p.Offset,
+				AfterCreatedAt: p.CreatedAt, AfterID: p.ID,
+				Desc: fmt.Sprintf("(created_at, id) cursor at row %d", p.Offset)},
+		)
+	}
+	return cases
+}
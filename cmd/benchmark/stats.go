@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Stats summarizes the latency distribution of a BenchmarkCase.
+type Stats struct {
+	Cold    time.Duration `json:"cold_ns"` // First execution, before any repeated runs
+	Min     time.Duration `json:"min_ns"`
+	P50     time.Duration `json:"p50_ns"`
+	P95     time.Duration `json:"p95_ns"`
+	P99     time.Duration `json:"p99_ns"`
+	Max     time.Duration `json:"max_ns"`
+	Mean    time.Duration `json:"mean_ns"`
+	StdDev  time.Duration `json:"stddev_ns"`
+	Samples int           `json:"samples"`
+}
+
+// computeStats derives Stats from the warm samples; cold is reported as-is.
+func computeStats(cold time.Duration, samples []time.Duration) Stats {
+	st := Stats{Cold: cold, Samples: len(samples)}
+	if len(samples) == 0 {
+		return st
+	}
+
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum float64
+	for _, d := range sorted {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(sorted))
+
+	var sq float64
+	for _, d := range sorted {
+		diff := float64(d) - mean
+		sq += diff * diff
+	}
+
+	st.Min = sorted[0]
+	st.Max = sorted[len(sorted)-1]
+	st.P50 = percentile(sorted, 50)
+	st.P95 = percentile(sorted, 95)
+	st.P99 = percentile(sorted, 99)
+	st.Mean = time.Duration(mean)
+	st.StdDev = time.Duration(math.Sqrt(sq / float64(len(sorted))))
+	return st
+}
+
+// percentile returns the p-th percentile of sorted using linear interpolation
+// between the closest ranks.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + time.Duration(frac*float64(sorted[upper]-sorted[lower]))
+}
+
+// roundDuration trims durations to microseconds for table output.
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// durationMs converts d to fractional milliseconds for CSV and Markdown output.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
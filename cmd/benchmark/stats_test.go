@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{name: "single sample", sorted: []time.Duration{7}, p: 99, want: 7},
+		{name: "min", sorted: []time.Duration{10, 20, 30}, p: 0, want: 10},
+		{name: "max", sorted: []time.Duration{10, 20, 30}, p: 100, want: 30},
+		{name: "exact rank", sorted: []time.Duration{10, 20, 30}, p: 50, want: 20},
+		{name: "interpolated median", sorted: []time.Duration{10, 20, 30, 40}, p: 50, want: 25},
+		{name: "interpolated p95", sorted: []time.Duration{0, 100}, p: 95, want: 95},
+		{name: "p99 of 1..100", sorted: seq(100), p: 99, want: 99}, // rank 98.01
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		cold    time.Duration
+		samples []time.Duration
+		want    Stats
+	}{
+		{name: "no samples", cold: 5, want: Stats{Cold: 5}},
+		{
+			name:    "single sample",
+			cold:    9,
+			samples: []time.Duration{4},
+			want:    Stats{Cold: 9, Min: 4, P50: 4, P95: 4, P99: 4, Max: 4, Mean: 4, Samples: 1},
+		},
+		{
+			name:    "unsorted",
+			samples: []time.Duration{4, 2, 9, 4, 5, 5, 7, 4},
+			want:    Stats{Min: 2, P50: 4, P95: 8, P99: 8, Max: 9, Mean: 5, StdDev: 2, Samples: 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before []time.Duration
+			before = append(before, tt.samples...)
+			if got := computeStats(tt.cold, tt.samples); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range before {
+				if tt.samples[i] != before[i] {
+					t.Fatalf("samples were reordered: %v", tt.samples)
+				}
+			}
+		})
+	}
+}
+
+// seq returns the durations 1..n.
+func seq(n int) []time.Duration {
+	s := make([]time.Duration, n)
+	for i := range s {
+		s[i] = time.Duration(i + 1)
+	}
+	return s
+}
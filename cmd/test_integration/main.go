@@ -27,7 +27,13 @@ func main() {
 		"record_count": 1000,
 		"content_size": "small",
 	}
-	if err := sendRequest("POST", "/initialize", initReq); err != nil {
+	var job struct {
+		JobID string `json:"job_id"`
+	}
+	if err := sendRequestDecode("POST", "/initialize", initReq, &job); err != nil {
+		fail(err)
+	}
+	if err := waitForJob(job.JobID, 2*time.Minute); err != nil {
 		fail(err)
 	}
 
@@ -74,6 +80,12 @@ func main() {
 }
 
 func sendRequest(method, endpoint string, body interface{}) error {
+	return sendRequestDecode(method, endpoint, body, nil)
+}
+
+// sendRequestDecode is sendRequest that also decodes the JSON response into
+// out when out is non-nil.
+func sendRequestDecode(method, endpoint string, body interface{}, out interface{}) error {
 	var bodyReader io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -101,9 +113,38 @@ func sendRequest(method, endpoint string, body interface{}) error {
 		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
 	}
 
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
 	return nil
 }
 
+// waitForJob polls an initialization job until it leaves the running state.
+func waitForJob(id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var status struct {
+			Status       string `json:"status"`
+			RowsInserted int64  `json:"rows_inserted"`
+			Error        string `json:"error"`
+		}
+		if err := sendRequestDecode("GET", "/jobs/"+id, nil, &status); err != nil {
+			return err
+		}
+
+		switch status.Status {
+		case "completed":
+			fmt.Printf("Job %s completed with %d rows\n", id, status.RowsInserted)
+			return nil
+		case "running":
+			time.Sleep(500 * time.Millisecond)
+		default:
+			return fmt.Errorf("job %s ended with status %s: %s", id, status.Status, status.Error)
+		}
+	}
+	return fmt.Errorf("job %s did not finish within %s", id, timeout)
+}
+
 func getLogs(params map[string]string) ([]map[string]interface{}, error) {
 	req, err := http.NewRequest("GET", baseURL+"/logs", nil)
 	if err != nil {
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"log-project/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// errCaptured short-circuits a sqlc query once its SQL has been recorded.
+var errCaptured = errors.New("query captured")
+
+// CapturedQuery is the SQL text and arguments a sqlc-generated method would
+// have sent to the database.
+type CapturedQuery struct {
+	SQL  string
+	Args []interface{}
+}
+
+// CaptureQuery calls fn with a db.Queries that records the first statement it
+// issues instead of executing it. This lets callers wrap generated queries,
+// e.g. in EXPLAIN, without duplicating their SQL.
+func CaptureQuery(fn func(q *db.Queries) error) (CapturedQuery, error) {
+	rec := &captureDBTX{}
+	err := fn(db.New(rec))
+	if !rec.captured {
+		if err == nil {
+			err = errors.New("no query was issued")
+		}
+		return CapturedQuery{}, err
+	}
+	return rec.query, nil
+}
+
+type captureDBTX struct {
+	query    CapturedQuery
+	captured bool
+}
+
+func (c *captureDBTX) record(sql string, args []interface{}) {
+	if !c.captured {
+		c.query = CapturedQuery{SQL: sql, Args: args}
+		c.captured = true
+	}
+}
+
+func (c *captureDBTX) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	c.record(sql, args)
+	return pgconn.CommandTag{}, errCaptured
+}
+
+func (c *captureDBTX) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	c.record(sql, args)
+	return nil, errCaptured
+}
+
+func (c *captureDBTX) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	c.record(sql, args)
+	return capturedRow{}
+}
+
+func (c *captureDBTX) CopyFrom(_ context.Context, _ pgx.Identifier, _ []string, _ pgx.CopyFromSource) (int64, error) {
+	return 0, errors.New("COPY cannot be captured")
+}
+
+type capturedRow struct{}
+
+func (capturedRow) Scan(...interface{}) error {
+	return errCaptured
+}
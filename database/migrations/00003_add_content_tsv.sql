@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Stored tsvector so FTS queries no longer re-run to_tsvector over content::text per row
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', content::text)) STORED;
+CREATE INDEX IF NOT EXISTS idx_logs_content_tsv ON logs USING GIN (content_tsv);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_content_tsv;
+ALTER TABLE logs DROP COLUMN IF EXISTS content_tsv;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- B-tree matching the keyset pagination order (created_at DESC, id DESC), so a
+-- page after a cursor is an index range scan instead of a sort
+CREATE INDEX IF NOT EXISTS idx_logs_created_at_id ON logs USING BTREE (created_at DESC, id DESC);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_created_at_id;
+-- +goose StatementEnd
\ No newline at end of file
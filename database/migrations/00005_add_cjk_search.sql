@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 'simple' keeps every word as-is (no stemming, no stop words), for queries
+-- in languages the english configuration would mangle
+CREATE INDEX IF NOT EXISTS idx_logs_content_simple ON logs USING GIN (to_tsvector('simple', content::text));
+-- Bigrams of the CJK text in content, filled in by the application at insert
+-- time (search.ContentCJK); NULL when content has no CJK text
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS content_cjk TEXT;
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS content_cjk_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', content_cjk)) STORED;
+CREATE INDEX IF NOT EXISTS idx_logs_content_cjk_tsv ON logs USING GIN (content_cjk_tsv);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_logs_content_cjk_tsv;
+ALTER TABLE logs DROP COLUMN IF EXISTS content_cjk_tsv;
+ALTER TABLE logs DROP COLUMN IF EXISTS content_cjk;
+DROP INDEX IF EXISTS idx_logs_content_simple;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The filters shared by /logs, /search/partial and /logs/export, so every
+-- query that lists, counts or exports logs applies the same predicate. Each
+-- NULL argument disables its filter. A single-SELECT SQL function is inlined
+-- by the planner, which then sees the plain predicates: content_tsv,
+-- content_cjk_tsv, the trigram and jsonb GIN indexes are used as before, and
+-- the NULL checks fold away for the filters a request leaves unset.
+CREATE OR REPLACE FUNCTION log_matches(
+    l logs,
+    user_id uuid,
+    domain text,
+    created_at_from timestamptz,
+    created_at_to timestamptz,
+    content_search text, -- plainto_tsquery text against content_tsv
+    content_query text,  -- to_tsquery text against content_tsv
+    simple_query text,   -- to_tsquery text, simple configuration
+    cjk_query text,      -- to_tsquery text against content_cjk_tsv
+    search_term text,    -- ILIKE substring of content::text
+    field_path text,     -- jsonpath limiting the searches above to the values it selects
+    json_contains jsonb, -- content @> json_contains
+    json_match text,     -- content @@ json_match
+    json_path text       -- content @@ json_path
+) RETURNS boolean
+LANGUAGE sql STABLE
+AS $$
+SELECT
+    (user_id IS NULL OR l.user_id = user_id) AND
+    (domain IS NULL OR l.domain = domain) AND
+    (created_at_from IS NULL OR l.created_at >= created_at_from) AND
+    (created_at_to IS NULL OR l.created_at <= created_at_to) AND
+    (content_search IS NULL OR l.content_tsv @@ plainto_tsquery('english', content_search)) AND
+    (content_query IS NULL OR l.content_tsv @@ to_tsquery('english', content_query)) AND
+    (simple_query IS NULL OR to_tsvector('simple', l.content::text) @@ to_tsquery('simple', simple_query)) AND
+    (cjk_query IS NULL OR l.content_cjk_tsv @@ to_tsquery('simple', cjk_query)) AND
+    (search_term IS NULL OR l.content::text ILIKE '%' || search_term || '%') AND
+    (field_path IS NULL OR (content_search IS NULL AND content_query IS NULL AND simple_query IS NULL) OR
+        jsonb_to_tsvector('english', jsonb_path_query_array(l.content, field_path::jsonpath, '{}', true), '["string", "numeric", "boolean"]')
+            @@ COALESCE(to_tsquery('english', content_query), plainto_tsquery('english', content_search)) OR
+        jsonb_to_tsvector('simple', jsonb_path_query_array(l.content, field_path::jsonpath, '{}', true), '["string", "numeric", "boolean"]')
+            @@ to_tsquery('simple', simple_query)) AND
+    -- A subquery would stop the function from being inlined, so the selected
+    -- values are matched as the text of one JSON array
+    (field_path IS NULL OR search_term IS NULL OR
+        jsonb_path_query_array(l.content, field_path::jsonpath, '{}', true)::text ILIKE '%' || search_term || '%') AND
+    (json_contains IS NULL OR l.content @> json_contains) AND
+    (json_match IS NULL OR l.content @@ json_match::jsonpath) AND
+    (json_path IS NULL OR l.content @@ json_path::jsonpath)
+$$;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS log_matches(logs, uuid, text, timestamptz, timestamptz, text, text, text, text, text, text, jsonb, text, text);
+-- +goose StatementEnd
\ No newline at end of file
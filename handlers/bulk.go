@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"log-project/importer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BulkIngestLogs godoc
+// @Summary Bulk ingest logs from NDJSON
+// @Description Stream newline-delimited JSON log records ({"user_id", "domain", "action", "content", "created_at"}) into the logs table with COPY FROM. The body is read line by line and never buffered whole; invalid lines are skipped and reported with their line number. Send Content-Encoding: gzip for compressed bodies
+// @Tags logs
+// @Accept application/x-ndjson
+// @Produce json
+// @Param batch_size query int false "Rows per COPY batch" default(1000)
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /logs/bulk [post]
+func (h *Handler) BulkIngestLogs(c *gin.Context) {
+	var query struct {
+		BatchSize int `form:"batch_size" binding:"omitempty,min=1,max=100000"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gzip body: " + err.Error()})
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	// Use the request context so a client that disconnects stops the COPY.
+	ctx := c.Request.Context()
+
+	loader := importer.NewLoader(h.queries, query.BatchSize)
+	summary, err := importer.LoadNDJSON(ctx, body, loader)
+
+	resp := gin.H{
+		"lines":              summary.Lines,
+		"inserted":           summary.Inserted,
+		"rejected":           summary.Rejected,
+		"bytes":              summary.Bytes,
+		"errors":             summary.Errors,
+		"errors_truncated":   summary.ErrorsTruncated,
+		"duration":           summary.Duration.String(),
+		"records_per_second": summary.RecordsPerSecond(),
+	}
+	if err != nil {
+		log.Printf("Bulk ingest stopped after %d lines: %v\n", summary.Lines, err)
+		resp["error"] = "Bulk ingest stopped: " + err.Error()
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	log.Printf("Bulk ingest: %d inserted, %d rejected in %s (%.2f records/sec)\n",
+		summary.Inserted, summary.Rejected, summary.Duration, summary.RecordsPerSecond())
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"log-project/database"
+	"log-project/internal/db"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Count modes for the total of a list response.
+const (
+	CountExact     = "exact"     // COUNT(*) over every matching row
+	CountEstimated = "estimated" // planner row estimate, or pg_class.reltuples without filters
+	CountCapped    = "capped"    // COUNT(*) that stops after MaxCappedCount rows
+	CountNone      = "none"      // no count at all
+)
+
+// estimateLogsCountQuery reads the row count as of the last VACUUM/ANALYZE;
+// -1 if the table was never analyzed. sqlc cannot resolve pg_class, so it is
+// run directly on the pool.
+const estimateLogsCountQuery = `SELECT reltuples::bigint FROM pg_class WHERE oid = 'logs'::regclass`
+
+// MaxCappedCount is where capped counts stop; a total of MaxCappedCount
+// means "at least MaxCappedCount".
+const MaxCappedCount = 10000
+
+// totalCount is the total reported with a page of logs.
+type totalCount struct {
+	Value int64
+	// AtLeast is set when a capped count hit MaxCappedCount.
+	AtLeast bool
+}
+
+// counter holds the count queries for one filter set.
+type counter struct {
+	exact  func(ctx context.Context, q *db.Queries) (int64, error)
+	capped func(ctx context.Context, q *db.Queries, maxCount int32) (int64, error)
+	// unfiltered reports whether the filters match the whole table, which
+	// lets estimated counts use pg_class.reltuples.
+	unfiltered bool
+}
+
+// count computes the total in the given mode. It returns nil for CountNone.
+func (h *Handler) count(ctx context.Context, mode string, cnt counter) (*totalCount, error) {
+	switch mode {
+	case CountNone:
+		return nil, nil
+	case CountCapped:
+		// Count one row past the cap to tell "exactly" from "at least"
+		n, err := cnt.capped(ctx, h.queries, MaxCappedCount+1)
+		if err != nil {
+			return nil, err
+		}
+		if n > MaxCappedCount {
+			return &totalCount{Value: MaxCappedCount, AtLeast: true}, nil
+		}
+		return &totalCount{Value: n}, nil
+	case CountEstimated:
+		if cnt.unfiltered {
+			var n int64
+			if err := h.pool.QueryRow(ctx, estimateLogsCountQuery).Scan(&n); err != nil {
+				return nil, err
+			}
+			// -1 until the first VACUUM/ANALYZE; ask the planner instead
+			if n >= 0 {
+				return &totalCount{Value: n}, nil
+			}
+		}
+		n, err := h.estimateRows(ctx, cnt.exact)
+		if err != nil {
+			return nil, err
+		}
+		return &totalCount{Value: n}, nil
+	default:
+		n, err := cnt.exact(ctx, h.queries)
+		if err != nil {
+			return nil, err
+		}
+		return &totalCount{Value: n}, nil
+	}
+}
+
+// estimateRows runs EXPLAIN on the COUNT(*) query issued by exact and returns
+// the planner's estimate of the rows it would count.
+func (h *Handler) estimateRows(ctx context.Context, exact func(ctx context.Context, q *db.Queries) (int64, error)) (int64, error) {
+	captured, err := database.CaptureQuery(func(q *db.Queries) error {
+		_, err := exact(ctx, q)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := h.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Parallel plans split the scan estimate across workers; a serial plan
+	// keeps the whole estimate on one node.
+	if _, err := tx.Exec(ctx, "SET LOCAL max_parallel_workers_per_gather = 0"); err != nil {
+		return 0, err
+	}
+
+	var raw []byte
+	if err := tx.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+captured.SQL, captured.Args...).Scan(&raw); err != nil {
+		return 0, err
+	}
+
+	type planNode struct {
+		NodeType string     `json:"Node Type"`
+		PlanRows float64    `json:"Plan Rows"`
+		Plans    []planNode `json:"Plans"`
+	}
+	var out []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(out) == 0 {
+		return 0, fmt.Errorf("empty plan")
+	}
+
+	// The COUNT(*) aggregate returns one row; the estimate we want is on
+	// the node that feeds it.
+	node := out[0].Plan
+	for node.NodeType == "Aggregate" && len(node.Plans) > 0 {
+		node = node.Plans[0]
+	}
+	return int64(node.PlanRows), nil
+}
+
+// withTotal adds the total, total_pages and count_mode fields to a list
+// response. Both totals are null in CountNone mode.
+func withTotal(resp gin.H, mode string, total *totalCount, limit int) gin.H {
+	resp["count_mode"] = mode
+	if total == nil {
+		resp["total"] = nil
+		resp["total_pages"] = nil
+		return resp
+	}
+	resp["total"] = total.Value
+	resp["total_pages"] = int((total.Value + int64(limit) - 1) / int64(limit))
+	if total.AtLeast {
+		resp["total_at_least"] = true
+	}
+	return resp
+}
+
+// ftsCounter counts the rows GetLogs lists.
+func (p logFilterParams) ftsCounter() counter {
+	return counter{
+		exact: func(ctx context.Context, q *db.Queries) (int64, error) {
+			return q.CountLogsWithFilters(ctx, db.CountLogsWithFiltersParams{
+				UserID:        p.UserID,
+				Domain:        p.Domain,
+				CreatedAtFrom: p.CreatedAtFrom,
+				CreatedAtTo:   p.CreatedAtTo,
+				ContentSearch: p.ContentSearch,
+				ContentQuery:  p.ContentQuery,
+				SimpleQuery:   p.SimpleQuery,
+				CjkQuery:      p.CjkQuery,
+				FieldPath:     p.FieldPath,
+				JsonContains:  p.JSONContains,
+				JsonMatch:     p.JSONMatch,
+				JsonPath:      p.JSONPath,
+			})
+		},
+		capped: func(ctx context.Context, q *db.Queries, maxCount int32) (int64, error) {
+			return q.CountLogsWithFiltersCapped(ctx, db.CountLogsWithFiltersCappedParams{
+				UserID:        p.UserID,
+				Domain:        p.Domain,
+				CreatedAtFrom: p.CreatedAtFrom,
+				CreatedAtTo:   p.CreatedAtTo,
+				ContentSearch: p.ContentSearch,
+				ContentQuery:  p.ContentQuery,
+				SimpleQuery:   p.SimpleQuery,
+				CjkQuery:      p.CjkQuery,
+				FieldPath:     p.FieldPath,
+				JsonContains:  p.JSONContains,
+				JsonMatch:     p.JSONMatch,
+				JsonPath:      p.JSONPath,
+				MaxCount:      maxCount,
+			})
+		},
+		unfiltered: !p.UserID.Valid && !p.Domain.Valid && !p.CreatedAtFrom.Valid && !p.CreatedAtTo.Valid && !p.ContentSearch.Valid && !p.ContentQuery.Valid &&
+			!p.SimpleQuery.Valid && !p.CjkQuery.Valid && !p.FieldPath.Valid &&
+			p.JSONContains == nil && !p.JSONMatch.Valid && !p.JSONPath.Valid,
+	}
+}
+
+// partialCounter counts the rows SearchLogsPartial lists.
+func (p logFilterParams) partialCounter() counter {
+	return counter{
+		exact: func(ctx context.Context, q *db.Queries) (int64, error) {
+			return q.CountLogsPartial(ctx, db.CountLogsPartialParams{
+				UserID:        p.UserID,
+				Domain:        p.Domain,
+				CreatedAtFrom: p.CreatedAtFrom,
+				CreatedAtTo:   p.CreatedAtTo,
+				SearchTerm:    p.SearchTerm,
+				FieldPath:     p.FieldPath,
+				JsonContains:  p.JSONContains,
+				JsonMatch:     p.JSONMatch,
+				JsonPath:      p.JSONPath,
+			})
+		},
+		capped: func(ctx context.Context, q *db.Queries, maxCount int32) (int64, error) {
+			return q.CountLogsPartialCapped(ctx, db.CountLogsPartialCappedParams{
+				UserID:        p.UserID,
+				Domain:        p.Domain,
+				CreatedAtFrom: p.CreatedAtFrom,
+				CreatedAtTo:   p.CreatedAtTo,
+				SearchTerm:    p.SearchTerm,
+				FieldPath:     p.FieldPath,
+				JsonContains:  p.JSONContains,
+				JsonMatch:     p.JSONMatch,
+				JsonPath:      p.JSONPath,
+				MaxCount:      maxCount,
+			})
+		},
+	}
+}
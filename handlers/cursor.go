@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"time"
+
+	"log-project/internal/db"
+	"log-project/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// keysetCursor is the (created_at, id) position of the last row of a page.
+// Clients get it as an opaque token and send it back to fetch the next page.
+type keysetCursor struct {
+	CreatedAt pgtype.Timestamptz
+	ID        pgtype.UUID
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor packs created_at (Unix microseconds, Postgres' precision) and
+// the 16 id bytes into a URL-safe base64 token.
+func encodeCursor(createdAt pgtype.Timestamptz, id pgtype.UUID) string {
+	var buf [24]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(createdAt.Time.UnixMicro()))
+	copy(buf[8:], id.Bytes[:])
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
+
+// decodeCursor parses a token from encodeCursor. The empty token is the start
+// of the result set and decodes to a NULL cursor.
+func decodeCursor(token string) (keysetCursor, error) {
+	if token == "" {
+		return keysetCursor{}, nil
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != 24 {
+		return keysetCursor{}, errInvalidCursor
+	}
+	micros := int64(binary.BigEndian.Uint64(buf[:8]))
+	cur := keysetCursor{
+		CreatedAt: pgtype.Timestamptz{Time: time.UnixMicro(micros).UTC(), Valid: true},
+		ID:        pgtype.UUID{Valid: true},
+	}
+	copy(cur.ID.Bytes[:], buf[8:])
+	return cur, nil
+}
+
+// listLogsKeyset serves GetLogs when a cursor is given.
+func (h *Handler) listLogsKeyset(c *gin.Context, filter models.LogFilter, params logFilterParams, queryStart time.Time) {
+	cur, err := decodeCursor(*filter.Cursor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	// Fetch one extra row to find out whether there is a next page
+	logs, err := h.queries.ListLogsWithFiltersKeyset(ctx, db.ListLogsWithFiltersKeysetParams{
+		UserID:         params.UserID,
+		Domain:         params.Domain,
+		CreatedAtFrom:  params.CreatedAtFrom,
+		CreatedAtTo:    params.CreatedAtTo,
+		ContentSearch:  params.ContentSearch,
+		ContentQuery:   params.ContentQuery,
+		SimpleQuery:    params.SimpleQuery,
+		CjkQuery:       params.CjkQuery,
+		FieldPath:      params.FieldPath,
+		JsonContains:   params.JSONContains,
+		JsonMatch:      params.JSONMatch,
+		JsonPath:       params.JSONPath,
+		AfterCreatedAt: cur.CreatedAt,
+		AfterID:        cur.ID,
+		Limit:          int32(filter.Limit + 1),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query logs"})
+		return
+	}
+
+	var next *string
+	if len(logs) > filter.Limit {
+		logs = logs[:filter.Limit]
+		last := logs[len(logs)-1]
+		token := encodeCursor(last.CreatedAt, last.ID)
+		next = &token
+	}
+
+	response := make([]map[string]interface{}, len(logs))
+	for i, log := range logs {
+		response[i] = logResponse(log.ID, log.UserID, log.Domain, log.Action, log.Content, log.CreatedAt)
+	}
+
+	// Keyset pages skip the count unless one is asked for
+	countMode := filter.Count
+	if countMode == "" {
+		countMode = CountNone
+	}
+	total, err := h.count(ctx, countMode, params.ftsCounter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count records"})
+		return
+	}
+
+	queryDuration := time.Since(queryStart)
+
+	c.JSON(http.StatusOK, withTotal(gin.H{
+		"data":           response,
+		"limit":          filter.Limit,
+		"next_cursor":    next,
+		"query_duration": queryDuration.String(),
+	}, countMode, total, filter.Limit))
+}
+
+// searchLogsPartialKeyset serves SearchLogsPartial when a cursor is given.
+func (h *Handler) searchLogsPartialKeyset(c *gin.Context, filter models.LogFilter, params logFilterParams, queryStart time.Time) {
+	cur, err := decodeCursor(*filter.Cursor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	// Fetch one extra row to find out whether there is a next page
+	logs, err := h.queries.SearchLogsPartialKeyset(ctx, db.SearchLogsPartialKeysetParams{
+		UserID:         params.UserID,
+		Domain:         params.Domain,
+		CreatedAtFrom:  params.CreatedAtFrom,
+		CreatedAtTo:    params.CreatedAtTo,
+		SearchTerm:     params.SearchTerm,
+		FieldPath:      params.FieldPath,
+		JsonContains:   params.JSONContains,
+		JsonMatch:      params.JSONMatch,
+		JsonPath:       params.JSONPath,
+		AfterCreatedAt: cur.CreatedAt,
+		AfterID:        cur.ID,
+		Limit:          pgtype.Int4{Int32: int32(filter.Limit + 1), Valid: true},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query logs"})
+		return
+	}
+
+	var next *string
+	if len(logs) > filter.Limit {
+		logs = logs[:filter.Limit]
+		last := logs[len(logs)-1]
+		token := encodeCursor(last.CreatedAt, last.ID)
+		next = &token
+	}
+
+	response := make([]map[string]interface{}, len(logs))
+	for i, log := range logs {
+		response[i] = logResponse(log.ID, log.UserID, log.Domain, log.Action, log.Content, log.CreatedAt)
+	}
+
+	// Keyset pages skip the count unless one is asked for
+	countMode := filter.Count
+	if countMode == "" {
+		countMode = CountNone
+	}
+	total, err := h.count(ctx, countMode, params.partialCounter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count records"})
+		return
+	}
+
+	queryDuration := time.Since(queryStart)
+
+	c.JSON(http.StatusOK, withTotal(gin.H{
+		"data":           response,
+		"limit":          filter.Limit,
+		"next_cursor":    next,
+		"query_duration": queryDuration.String(),
+	}, countMode, total, filter.Limit))
+}
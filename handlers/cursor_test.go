@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := pgtype.UUID{Bytes: [16]byte{0x01, 0x8f, 0x2a, 0xff, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0xfe, 0xff}, Valid: true}
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{name: "utc", at: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC), want: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)},
+		{name: "nanoseconds truncated", at: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), want: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)},
+		{name: "zone normalized to utc", at: time.Date(2024, 5, 1, 21, 30, 0, 0, time.FixedZone("JST", 9*3600)), want: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)},
+		{name: "before epoch", at: time.Date(1960, 1, 1, 0, 0, 0, 1000, time.UTC), want: time.Date(1960, 1, 1, 0, 0, 0, 1000, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := encodeCursor(pgtype.Timestamptz{Time: tt.at, Valid: true}, id)
+			if strings.ContainsAny(token, "+/=") {
+				t.Fatalf("token %q is not URL-safe", token)
+			}
+			cur, err := decodeCursor(token)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", token, err)
+			}
+			if !cur.CreatedAt.Valid || !cur.CreatedAt.Time.Equal(tt.want) || cur.CreatedAt.Time.Location() != time.UTC {
+				t.Errorf("CreatedAt = %v (valid %v), want %v", cur.CreatedAt.Time, cur.CreatedAt.Valid, tt.want)
+			}
+			if cur.ID != id {
+				t.Errorf("ID = %v, want %v", cur.ID, id)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "empty is the first page", token: ""},
+		{name: "not base64", token: "not a cursor!", wantErr: true},
+		{name: "padding", token: base64.RawURLEncoding.EncodeToString(make([]byte, 24)) + "=", wantErr: true},
+		{name: "standard alphabet", token: strings.Repeat("+", 32), wantErr: true},
+		{name: "too short", token: base64.RawURLEncoding.EncodeToString(make([]byte, 23)), wantErr: true},
+		{name: "too long", token: base64.RawURLEncoding.EncodeToString(make([]byte, 25)), wantErr: true},
+		{name: "multi-byte", token: "カーソル", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur, err := decodeCursor(tt.token)
+			if tt.wantErr {
+				if err != errInvalidCursor {
+					t.Fatalf("got %+v, %v; want errInvalidCursor", cur, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cur.CreatedAt.Valid || cur.ID.Valid {
+				t.Errorf("got %+v, want a NULL cursor", cur)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"log-project/database"
+	"log-project/internal/db"
+	"log-project/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Export formats accepted by ExportLogs.
+const (
+	ExportNDJSON = "ndjson"
+	ExportCSV    = "csv"
+)
+
+// defaultExportChunk is how many rows each FETCH pulls from the cursor; the
+// response is flushed to the client after every chunk.
+const defaultExportChunk = 1000
+
+// exportCursor is declared over the SQL of db.ExportLogs, so the export
+// filters rows exactly as /logs and /search/partial do.
+const exportCursor = "DECLARE export_cursor NO SCROLL CURSOR FOR "
+
+var exportColumns = []string{"id", "user_id", "domain", "action", "created_at", "content"}
+
+// ExportLogs godoc
+// @Summary Export filtered logs
+// @Description Stream every log matching the filters as NDJSON or CSV. Rows are read from a server-side cursor and written in chunks, so the result set is never held in memory. The response is gzip-compressed when gzip=true or the client's Accept-Encoding allows gzip
+// @Tags logs
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Param format query string false "Output format" Enums(ndjson, csv) default(ndjson)
+// @Param gzip query bool false "Compress the response with gzip"
+// @Param chunk_size query int false "Rows fetched from the cursor per chunk" default(1000)
+// @Param user_id query string false "User ID"
+// @Param domain query string false "Domain"
+// @Param created_at query string false "Created at from (YYYY-MM-DD)"
+// @Param created_at_to query string false "Created at to (YYYY-MM-DD)"
+// @Param content_like query string false "Full-text search in content"
+// @Param syntax query string false "content_like syntax" Enums(plain, websearch) default(plain)
+// @Param ts_config query string false "content_like text-search configuration" Enums(english, simple, cjk) default(english)
+// @Param search_term query string false "Partial match in content"
+// @Param field query string false "Limit content_like and search_term to a JSON path, e.g. content.status or nested_obj_*.nested_field_0"
+// @Param json_eq query []string false "Content key equals a value, path:value (e.g. status:error); repeatable" collectionFormat(multi)
+// @Param json_has query []string false "Content key exists, e.g. nested_obj_1; repeatable" collectionFormat(multi)
+// @Param json_range query []string false "Numeric comparison on a content key, e.g. duration>1000 (>, >=, <, <=); repeatable" collectionFormat(multi)
+// @Param jsonpath query string false "Raw jsonpath predicate on content, e.g. $.status == \"error\" && $.duration > 4000"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /logs/export [get]
+func (h *Handler) ExportLogs(c *gin.Context) {
+	var query struct {
+		Format    string `form:"format" binding:"omitempty,oneof=ndjson csv"`
+		Gzip      bool   `form:"gzip"`
+		ChunkSize int    `form:"chunk_size" binding:"omitempty,min=1,max=100000"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var filter models.LogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.Format == "" {
+		query.Format = ExportNDJSON
+	}
+	if query.ChunkSize == 0 {
+		query.ChunkSize = defaultExportChunk
+	}
+	params, err := parseLogFilter(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if params.FieldPath.Valid && !params.hasContentQuery() && !params.SearchTerm.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "field requires content_like or search_term"})
+		return
+	}
+
+	// Use the request context so a client that disconnects stops the export.
+	ctx := c.Request.Context()
+
+	if !h.checkJSONPath(ctx, c, params) {
+		return
+	}
+
+	tx, err := h.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Printf("Failed to start export: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start export"})
+		return
+	}
+	// Read-only, so rolling back is all the cleanup needed; it also closes
+	// the cursor. ctx is already cancelled when the client has gone away, so
+	// the rollback must not use it.
+	defer tx.Rollback(context.Background())
+
+	captured, err := database.CaptureQuery(func(q *db.Queries) error {
+		_, err := q.ExportLogs(ctx, db.ExportLogsParams{
+			UserID:        params.UserID,
+			Domain:        params.Domain,
+			CreatedAtFrom: params.CreatedAtFrom,
+			CreatedAtTo:   params.CreatedAtTo,
+			ContentSearch: params.ContentSearch,
+			ContentQuery:  params.ContentQuery,
+			SimpleQuery:   params.SimpleQuery,
+			CjkQuery:      params.CjkQuery,
+			SearchTerm:    params.SearchTerm,
+			FieldPath:     params.FieldPath,
+			JsonContains:  params.JSONContains,
+			JsonMatch:     params.JSONMatch,
+			JsonPath:      params.JSONPath,
+		})
+		return err
+	})
+	if err == nil {
+		_, err = tx.Exec(ctx, exportCursor+captured.SQL, captured.Args...)
+	}
+	if err != nil {
+		log.Printf("Failed to open export cursor: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export cursor"})
+		return
+	}
+
+	compress := query.Gzip || acceptsGzip(c.GetHeader("Accept-Encoding"))
+	filename := "logs-" + time.Now().UTC().Format("20060102-150405") + "." + query.Format
+
+	header := c.Writer.Header()
+	if query.Format == ExportCSV {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+	}
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	header.Set("Vary", "Accept-Encoding")
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+	}
+	// No Content-Length: flushing each chunk makes the response chunked.
+	c.Status(http.StatusOK)
+
+	var out io.Writer = c.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(c.Writer)
+		out = gz
+	}
+	w := newExportWriter(query.Format, out)
+
+	start := time.Now()
+	var total int64
+	fetch := fmt.Sprintf("FETCH %d FROM export_cursor", query.ChunkSize)
+	for {
+		n, err := exportChunk(ctx, tx, fetch, w)
+		total += int64(n)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err == nil && gz != nil {
+			err = gz.Flush()
+		}
+		if err != nil {
+			// The status line is already sent, so the only signal left to
+			// the client is the truncated body.
+			log.Printf("Export stopped after %d rows: %v\n", total, err)
+			return
+		}
+		c.Writer.Flush()
+		if n < query.ChunkSize {
+			break
+		}
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			log.Printf("Export stopped after %d rows: %v\n", total, err)
+			return
+		}
+	}
+	log.Printf("Exported %d rows as %s in %s\n", total, query.Format, time.Since(start))
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip: gzip (or
+// x-gzip) with a non-zero q-value, or failing that a non-zero "*".
+func acceptsGzip(header string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				v = 0
+			}
+			q = v
+		}
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// exportChunk fetches the next chunk from the cursor and writes it, returning
+// the number of rows read.
+func exportChunk(ctx context.Context, tx pgx.Tx, fetch string, w exportWriter) (int, error) {
+	rows, err := tx.Query(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var r db.ExportLogsRow
+		if err := rows.Scan(&r.ID, &r.UserID, &r.Domain, &r.Action, &r.Content, &r.CreatedAt); err != nil {
+			return n, err
+		}
+		n++
+		if err := w.Write(r); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// exportWriter encodes rows in one export format. Flush pushes buffered
+// output to the underlying writer.
+type exportWriter interface {
+	Write(r db.ExportLogsRow) error
+	Flush() error
+}
+
+func newExportWriter(format string, out io.Writer) exportWriter {
+	if format == ExportCSV {
+		w := &csvExportWriter{w: csv.NewWriter(out)}
+		w.err = w.w.Write(exportColumns)
+		return w
+	}
+	return &ndjsonExportWriter{w: bufio.NewWriterSize(out, 64*1024)}
+}
+
+type ndjsonExportWriter struct {
+	w *bufio.Writer
+}
+
+func (e *ndjsonExportWriter) Write(r db.ExportLogsRow) error {
+	line, err := json.Marshal(struct {
+		ID        string          `json:"id"`
+		UserID    string          `json:"user_id"`
+		Domain    string          `json:"domain"`
+		Action    string          `json:"action"`
+		Content   json.RawMessage `json:"content"`
+		CreatedAt time.Time       `json:"created_at"`
+	}{uuidToString(r.ID), uuidToString(r.UserID), r.Domain, r.Action, r.Content, r.CreatedAt.Time})
+	if err != nil {
+		return err
+	}
+	e.w.Write(line)
+	return e.w.WriteByte('\n')
+}
+
+func (e *ndjsonExportWriter) Flush() error {
+	return e.w.Flush()
+}
+
+type csvExportWriter struct {
+	w   *csv.Writer
+	err error
+}
+
+func (e *csvExportWriter) Write(r db.ExportLogsRow) error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Write([]string{
+		uuidToString(r.ID),
+		uuidToString(r.UserID),
+		r.Domain,
+		r.Action,
+		r.CreatedAt.Time.Format(time.RFC3339Nano),
+		string(r.Content),
+	})
+}
+
+func (e *csvExportWriter) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"log-project/models"
+	"log-project/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Query syntaxes for content_like.
+const (
+	SyntaxPlain     = "plain"     // words, all of which must match (plainto_tsquery)
+	SyntaxWebSearch = "websearch" // phrases, OR, -exclusions and prefix* (search.ParseWebSearch)
+)
+
+// Text-search configurations content_like can run against.
+const (
+	TSConfigEnglish = "english" // stemmed english words (content_tsv)
+	TSConfigSimple  = "simple"  // words as written, no stemming or stop words (not indexed, see migration 00007)
+	TSConfigCJK     = "cjk"     // bigrams of Chinese, Japanese and Korean text (content_cjk_tsv)
+)
+
+// logFilterParams is a models.LogFilter converted to query parameters. Unset
+// or unparseable filters stay NULL, which the queries treat as "no filter".
+// Only a content_like that fails to parse for its syntax and ts_config, or a
+// malformed field or JSON filter, is an error, so it can be reported as a 400.
+type logFilterParams struct {
+	UserID        pgtype.UUID
+	Domain        pgtype.Text
+	CreatedAtFrom pgtype.Timestamptz
+	CreatedAtTo   pgtype.Timestamptz
+	ContentSearch pgtype.Text // content_like, full-text search (plainto_tsquery)
+	ContentQuery  pgtype.Text // content_like with syntax=websearch, as to_tsquery text
+	SimpleQuery   pgtype.Text // content_like with ts_config=simple, as to_tsquery text
+	CjkQuery      pgtype.Text // content_like with ts_config=cjk, as to_tsquery text over bigrams
+	SearchTerm    pgtype.Text // search_term, partial match
+	FieldPath     pgtype.Text // field, as a jsonpath selecting the values content_like and search_term match
+	JSONContains  []byte      // json_eq, as one containment document (content @>)
+	JSONMatch     pgtype.Text // json_has and json_range, as a jsonpath predicate (content @@)
+	JSONPath      pgtype.Text // jsonpath, a raw jsonpath predicate (content @@)
+}
+
+func parseLogFilter(filter models.LogFilter) (logFilterParams, error) {
+	var p logFilterParams
+
+	if filter.UserID != nil && *filter.UserID != "" {
+		parsedUUID, err := uuid.Parse(*filter.UserID)
+		if err == nil {
+			p.UserID = pgtype.UUID{Bytes: parsedUUID, Valid: true}
+		}
+	}
+
+	if filter.Domain != nil && *filter.Domain != "" {
+		p.Domain = pgtype.Text{String: *filter.Domain, Valid: true}
+	}
+
+	if filter.CreatedAt != nil && *filter.CreatedAt != "" {
+		t, err := time.Parse("2006-01-02", *filter.CreatedAt)
+		if err == nil {
+			p.CreatedAtFrom = pgtype.Timestamptz{Time: t, Valid: true}
+		}
+	}
+
+	if filter.CreatedAtTo != nil && *filter.CreatedAtTo != "" {
+		t, err := time.Parse("2006-01-02", *filter.CreatedAtTo)
+		if err == nil {
+			// Set to end of day
+			t = t.Add(24*time.Hour - time.Second)
+			p.CreatedAtTo = pgtype.Timestamptz{Time: t, Valid: true}
+		}
+	}
+
+	if filter.ContentLike != nil && *filter.ContentLike != "" {
+		webSearch := filter.Syntax == SyntaxWebSearch
+		switch filter.TSConfig {
+		case TSConfigSimple:
+			parse := search.PlainQuery
+			if webSearch {
+				parse = search.ParseWebSearch
+			}
+			query, err := parse(*filter.ContentLike)
+			if err != nil {
+				return p, fmt.Errorf("invalid content_like: %w", err)
+			}
+			p.SimpleQuery = pgtype.Text{String: query, Valid: true}
+		case TSConfigCJK:
+			parse := search.PlainQueryCJK
+			if webSearch {
+				parse = search.ParseWebSearchCJK
+			}
+			query, err := parse(*filter.ContentLike)
+			if err != nil {
+				return p, fmt.Errorf("invalid content_like: %w", err)
+			}
+			p.CjkQuery = pgtype.Text{String: query, Valid: true}
+		default:
+			if webSearch {
+				query, err := search.ParseWebSearch(*filter.ContentLike)
+				if err != nil {
+					return p, fmt.Errorf("invalid content_like: %w", err)
+				}
+				p.ContentQuery = pgtype.Text{String: query, Valid: true}
+			} else {
+				p.ContentSearch = pgtype.Text{String: *filter.ContentLike, Valid: true}
+			}
+		}
+	}
+
+	if filter.SearchTerm != nil && *filter.SearchTerm != "" {
+		p.SearchTerm = pgtype.Text{String: *filter.SearchTerm, Valid: true}
+	}
+
+	if filter.Field != nil && *filter.Field != "" {
+		path, err := search.ParseField(*filter.Field)
+		if err != nil {
+			return p, fmt.Errorf("invalid field: %w", err)
+		}
+		p.FieldPath = pgtype.Text{String: path, Valid: true}
+		// Bigrams are only kept for the whole document, not per value
+		if p.CjkQuery.Valid {
+			return p, fmt.Errorf("field is not supported with ts_config=cjk")
+		}
+	}
+
+	if len(filter.JSONEq) > 0 || len(filter.JSONHas) > 0 || len(filter.JSONRange) > 0 {
+		jf, err := search.ParseJSONFilter(filter.JSONEq, filter.JSONHas, filter.JSONRange)
+		if err != nil {
+			return p, fmt.Errorf("invalid JSON filter: %w", err)
+		}
+		p.JSONContains = jf.Contains
+		if jf.Match != "" {
+			p.JSONMatch = pgtype.Text{String: jf.Match, Valid: true}
+		}
+	}
+
+	if filter.JSONPath != nil && *filter.JSONPath != "" {
+		p.JSONPath = pgtype.Text{String: *filter.JSONPath, Valid: true}
+	}
+
+	return p, nil
+}
+
+// checkJSONPath has PostgreSQL parse the raw jsonpath filter, so a syntax
+// error is reported as a 400 instead of failing the search with a 500. It
+// writes the error response and returns false when the request should stop.
+func (h *Handler) checkJSONPath(ctx context.Context, c *gin.Context, p logFilterParams) bool {
+	if !p.JSONPath.Valid {
+		return true
+	}
+	err := h.queries.ValidateJSONPath(ctx, p.JSONPath.String)
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &pgErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid jsonpath: " + pgErr.Message})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check jsonpath"})
+	}
+	return false
+}
+
+// hasContentQuery reports whether content_like is set, in any syntax and
+// ts_config.
+func (p logFilterParams) hasContentQuery() bool {
+	return p.ContentSearch.Valid || p.ContentQuery.Valid || p.SimpleQuery.Valid || p.CjkQuery.Valid
+}
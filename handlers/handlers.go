@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
 	"time"
 
+	"log-project/config"
 	"log-project/internal/db"
 	"log-project/models"
+	"log-project/seeder"
 	"log-project/utils"
 
 	"github.com/gin-gonic/gin"
@@ -22,23 +23,28 @@ import (
 type Handler struct {
 	pool    *pgxpool.Pool
 	queries *db.Queries
+	jobs    *jobManager
+	cfg     *config.Config
 }
 
-func New(pool *pgxpool.Pool) *Handler {
+func New(pool *pgxpool.Pool, cfg *config.Config) *Handler {
 	return &Handler{
 		pool:    pool,
 		queries: db.New(pool),
+		jobs:    newJobManager(),
+		cfg:     cfg,
 	}
 }
 
 // InitializeData godoc
 // @Summary Initialize database with sample data
-// @Description Generate and insert sample logs into the database using COPY FROM for optimal performance
+// @Description Start a background job that generates sample logs and inserts them using COPY FROM. Poll /jobs/{id} for progress
 // @Tags initialization
 // @Accept json
 // @Produce json
 // @Param request body models.InitializeRequest true "Initialization parameters"
-// @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /initialize [post]
 func (h *Handler) InitializeData(c *gin.Context) {
 	var req models.InitializeRequest
@@ -47,80 +53,119 @@ func (h *Handler) InitializeData(c *gin.Context) {
 		return
 	}
 
-	// Start timing
-	start := time.Now()
-
-	// Generate all data first
-	log.Printf("Generating %d records with %s content size...\n", req.RecordCount, req.ContentSize)
-
-	batchSize := 1000
-	totalBatches := (req.RecordCount + batchSize - 1) / batchSize
-
-	ctx := context.Background()
-	totalInserted := int64(0)
-
-	for batch := 0; batch < totalBatches; batch++ {
-		currentBatchSize := batchSize
-		if batch == totalBatches-1 {
-			currentBatchSize = req.RecordCount - (batch * batchSize)
+	// An inline profile replaces the built-in content sizes.
+	contentSize := req.ContentSize
+	var profile *utils.Profile
+	if len(req.Profile) > 0 && string(req.Profile) != "null" {
+		p, err := utils.ParseProfile(req.Profile, "json")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		userID := uuid.New()
-		domain := getRandomDomain()
-		params := make([]db.BulkInsertLogsParams, currentBatchSize)
-
-		for i := 0; i < currentBatchSize; i++ {
-			action := getRandomAction()
-			content := utils.GenerateSampleContent(req.ContentSize)
-			createdAt := time.Now().Add(-time.Duration(rand.Intn(86400*30)) * time.Second)
-
-			// Convert content to JSON bytes
-			contentBytes, err := json.Marshal(content)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal content"})
-				return
-			}
-
-			params[i] = db.BulkInsertLogsParams{
-				UserID:  pgtype.UUID{Bytes: userID, Valid: true},
-				Domain:  domain,
-				Action:  action,
-				Content: contentBytes,
-				CreatedAt: pgtype.Timestamptz{
-					Time:  createdAt,
-					Valid: true,
-				},
-			}
+		profile = p
+		if profile.Name == "" {
+			profile.Name = "custom"
 		}
+	} else if contentSize == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content_size or profile is required"})
+		return
+	}
 
-		// Use CopyFrom for bulk insert
-		rowsInserted, err := h.queries.BulkInsertLogs(ctx, params)
+	opts := seeder.Options{
+		RecordCount:    req.RecordCount,
+		ContentSize:    req.ContentSize,
+		Profile:        profile,
+		TargetRowBytes: req.TargetRowBytes,
+		Seed:           req.Seed,
+		Generators:     req.Generators,
+		Writers:        req.Writers,
+
+		Users:              req.Users,
+		UserDistribution:   toDistribution(req.UserDistribution),
+		DomainDistribution: toDistribution(req.DomainDistribution),
+		ActionDistribution: toDistribution(req.ActionDistribution),
+		TimePattern:        req.TimePattern,
+		TimeWindow:         time.Duration(req.TimeWindowDays) * 24 * time.Hour,
+	}
+
+	switch {
+	case req.RecordCount > 0 && req.TargetTotalBytes > 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "record_count and target_total_bytes are mutually exclusive"})
+		return
+	case req.TargetTotalBytes > 0:
+		count, err := seeder.EstimateRecordCount(c.Request.Context(), h.pool, opts, req.TargetTotalBytes)
 		if err != nil {
-			log.Printf("Failed to insert batch %d: %v\n", batch, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to insert batch: %v", err)})
+			log.Printf("Failed to estimate record count: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate record count"})
 			return
 		}
-
-		totalInserted += rowsInserted
-
-		// Progress feedback
-		progress := float64(batch+1) / float64(totalBatches) * 100
-		log.Printf("Progress: %.2f%% (Inserted %d rows in batch %d)\n", progress, rowsInserted, batch+1)
+		opts.RecordCount = count
+		if opts.RecordCount < 1 {
+			opts.RecordCount = 1
+		}
+	}
+	if opts.RecordCount > h.cfg.MaxRecordCount {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("%d records exceeds the maximum of %d (MAX_RECORD_COUNT)", opts.RecordCount, h.cfg.MaxRecordCount),
+		})
+		return
+	}
+	if profile != nil {
+		contentSize = "profile:" + profile.Name
+	}
+	if req.TargetRowBytes > 0 {
+		contentSize = fmt.Sprintf("%s@%dB", contentSize, req.TargetRowBytes)
 	}
 
-	duration := time.Since(start)
-	recordsPerSecond := float64(totalInserted) / duration.Seconds()
+	job, started := h.jobs.start(&Job{
+		RecordCount: opts.RecordCount,
+		ContentSize: contentSize,
+		Seed:        req.Seed,
+	}, func(ctx context.Context, job *Job) (seeder.Result, error) {
+		log.Printf("Job %s: generating %d records with %s content size...\n", job.ID, opts.RecordCount, contentSize)
+
+		result, err := seeder.Run(ctx, h.pool, opts, func(p seeder.Progress) {
+			job.update(p)
+			log.Printf("Job %s: %.2f%% (Inserted %d rows in batch %d)\n", job.ID, p.Percent(), p.BatchRows, p.Batch)
+		})
+		if err != nil {
+			log.Printf("Job %s: failed to initialize data: %v\n", job.ID, err)
+			return result, err
+		}
 
-	log.Printf("Completed! Inserted %d records in %s (%.2f records/sec)\n", totalInserted, duration, recordsPerSecond)
+		log.Printf("Job %s: completed! Inserted %d records in %s (%.2f records/sec)\n", job.ID, result.Inserted, result.Duration, result.RecordsPerSecond())
+		log.Printf("Job %s: generate %d workers %.0f records/sec (%.0f%% busy), write %d workers %.0f records/sec (%.0f%% busy)\n",
+			job.ID,
+			result.Generate.Workers, result.Generate.RecordsPerSecond(), result.Generate.Utilization(result.Duration)*100,
+			result.Write.Workers, result.Write.RecordsPerSecond(), result.Write.Utilization(result.Duration)*100)
+		return result, nil
+	})
+	if !started {
+		c.JSON(http.StatusConflict, gin.H{"error": "An initialization job is already running", "job_id": job.ID})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":            "Data initialized successfully",
-		"record_count":       totalInserted,
-		"content_size":       req.ContentSize,
-		"duration":           duration.String(),
-		"records_per_second": fmt.Sprintf("%.2f", recordsPerSecond),
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":      "Data initialization started",
+		"job_id":       job.ID,
+		"record_count": opts.RecordCount,
+		"content_size": contentSize,
+		"status_url":   "/api/jobs/" + job.ID,
 	})
 }
 
+func toDistribution(spec *models.DistributionSpec) seeder.Distribution {
+	if spec == nil {
+		return seeder.Distribution{}
+	}
+	return seeder.Distribution{
+		Kind:     spec.Type,
+		Skew:     spec.Skew,
+		HotKeys:  spec.HotKeys,
+		HotShare: spec.HotShare,
+	}
+}
+
 // GetLogs godoc
 // @Summary Get logs with filtering
 // @Description Retrieve logs with optional filtering parameters
@@ -132,8 +177,19 @@ func (h *Handler) InitializeData(c *gin.Context) {
 // @Param created_at query string false "Created date from filter (YYYY-MM-DD)"
 // @Param created_at_to query string false "Created date to filter (YYYY-MM-DD)"
 // @Param content_like query string false "Content search filter"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(50)
+// @Param syntax query string false "content_like syntax: plain words, or websearch (\"phrase\", OR, -exclude, prefix*)" Enums(plain, websearch) default(plain)
+// @Param ts_config query string false "Text-search configuration for content_like: english (stemmed), simple (words as written) or cjk (Chinese/Japanese/Korean bigrams)" Enums(english, simple, cjk) default(english)
+// @Param field query string false "Limit content_like to a JSON path, e.g. content.status or nested_obj_*.nested_field_0"
+// @Param json_eq query []string false "Content key equals a value, path:value (e.g. status:error); repeatable" collectionFormat(multi)
+// @Param json_has query []string false "Content key exists, e.g. nested_obj_1; repeatable" collectionFormat(multi)
+// @Param json_range query []string false "Numeric comparison on a content key, e.g. duration>1000 (>, >=, <, <=); repeatable" collectionFormat(multi)
+// @Param jsonpath query string false "Raw jsonpath predicate on content, e.g. $.status == \"error\" && $.duration > 4000"
+// @Param page query int false "Page number, at most 1000000" default(1)
+// @Param limit query int false "Items per page, at most 1000" default(50)
+// @Param cursor query string false "Keyset pagination cursor (next_cursor of the previous page); send it empty for the first page. Replaces page"
+// @Param count query string false "Total count mode (default exact, or none with cursor)" Enums(exact, estimated, capped, none)
+// @Param sort query string false "Order by created_at, or by relevance to content_like (adds rank and a highlighted headline to each row)" Enums(created_at, relevance) default(created_at)
+// @Param rank_fn query string false "Ranking function for sort=relevance" Enums(ts_rank, ts_rank_cd) default(ts_rank)
 // @Success 200 {object} map[string]interface{}
 // @Router /logs [get]
 func (h *Handler) GetLogs(c *gin.Context) {
@@ -149,51 +205,36 @@ func (h *Handler) GetLogs(c *gin.Context) {
 	queryStart := time.Now()
 
 	// Build filter parameters
-	var userID pgtype.UUID
-	var domain pgtype.Text
-	var createdAtFrom pgtype.Timestamptz
-	var createdAtTo pgtype.Timestamptz
-	var contentSearch pgtype.Text
-
-	if filter.UserID != nil && *filter.UserID != "" {
-		parsedUUID, err := uuid.Parse(*filter.UserID)
-		if err == nil {
-			userID = pgtype.UUID{Bytes: parsedUUID, Valid: true}
-		}
+	params, err := parseLogFilter(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	if filter.Domain != nil && *filter.Domain != "" {
-		domain = pgtype.Text{String: *filter.Domain, Valid: true}
+	if params.FieldPath.Valid && !params.hasContentQuery() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "field requires content_like"})
+		return
 	}
-
-	if filter.CreatedAt != nil && *filter.CreatedAt != "" {
-		t, err := time.Parse("2006-01-02", *filter.CreatedAt)
-		if err == nil {
-			createdAtFrom = pgtype.Timestamptz{Time: t, Valid: true}
-		}
+	if !h.checkJSONPath(ctx, c, params) {
+		return
 	}
 
-	if filter.CreatedAtTo != nil && *filter.CreatedAtTo != "" {
-		t, err := time.Parse("2006-01-02", *filter.CreatedAtTo)
-		if err == nil {
-			// Set to end of day
-			t = t.Add(24*time.Hour - time.Second)
-			createdAtTo = pgtype.Timestamptz{Time: t, Valid: true}
-		}
+	if filter.Sort == SortRelevance {
+		h.listLogsRanked(c, filter, params, queryStart)
+		return
 	}
 
-	if filter.ContentLike != nil && *filter.ContentLike != "" {
-		contentSearch = pgtype.Text{String: *filter.ContentLike, Valid: true}
+	// A cursor switches to keyset pagination, which skips the count by default
+	if filter.Cursor != nil {
+		h.listLogsKeyset(c, filter, params, queryStart)
+		return
 	}
 
 	// Count total records
-	total, err := h.queries.CountLogsWithFilters(ctx, db.CountLogsWithFiltersParams{
-		UserID:        userID,
-		Domain:        domain,
-		CreatedAtFrom: createdAtFrom,
-		CreatedAtTo:   createdAtTo,
-		ContentSearch: contentSearch,
-	})
+	countMode := filter.Count
+	if countMode == "" {
+		countMode = CountExact
+	}
+	total, err := h.count(ctx, countMode, params.ftsCounter())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count records"})
 		return
@@ -205,11 +246,18 @@ func (h *Handler) GetLogs(c *gin.Context) {
 
 	// Fetch logs
 	logs, err := h.queries.ListLogsWithFilters(ctx, db.ListLogsWithFiltersParams{
-		UserID:        userID,
-		Domain:        domain,
-		CreatedAtFrom: createdAtFrom,
-		CreatedAtTo:   createdAtTo,
-		ContentSearch: contentSearch,
+		UserID:        params.UserID,
+		Domain:        params.Domain,
+		CreatedAtFrom: params.CreatedAtFrom,
+		CreatedAtTo:   params.CreatedAtTo,
+		ContentSearch: params.ContentSearch,
+		ContentQuery:  params.ContentQuery,
+		SimpleQuery:   params.SimpleQuery,
+		CjkQuery:      params.CjkQuery,
+		FieldPath:     params.FieldPath,
+		JsonContains:  params.JSONContains,
+		JsonMatch:     params.JSONMatch,
+		JsonPath:      params.JSONPath,
 		Limit:         limit,
 		Offset:        offset,
 	})
@@ -223,31 +271,15 @@ func (h *Handler) GetLogs(c *gin.Context) {
 	// Convert to response format
 	response := make([]map[string]interface{}, len(logs))
 	for i, log := range logs {
-		var content map[string]interface{}
-		if err := json.Unmarshal(log.Content, &content); err != nil {
-			content = map[string]interface{}{"raw": string(log.Content)}
-		}
-
-		response[i] = map[string]interface{}{
-			"id":         uuidToString(log.ID),
-			"user_id":    uuidToString(log.UserID),
-			"domain":     log.Domain,
-			"action":     log.Action,
-			"content":    content,
-			"created_at": log.CreatedAt.Time,
-		}
+		response[i] = logResponse(log.ID, log.UserID, log.Domain, log.Action, log.Content, log.CreatedAt)
 	}
 
-	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
-
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withTotal(gin.H{
 		"data":           response,
-		"total":          total,
 		"page":           filter.Page,
 		"limit":          filter.Limit,
-		"total_pages":    totalPages,
 		"query_duration": queryDuration.String(),
-	})
+	}, countMode, total, filter.Limit))
 }
 
 // TruncateDatabase godoc
@@ -283,8 +315,15 @@ func (h *Handler) TruncateDatabase(c *gin.Context) {
 // @Param created_at query string false "Created date from filter (YYYY-MM-DD)"
 // @Param created_at_to query string false "Created date to filter (YYYY-MM-DD)"
 // @Param search_term query string true "Partial search term"
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(50)
+// @Param field query string false "Limit search_term to a JSON path, e.g. content.status or nested_obj_*.nested_field_0"
+// @Param json_eq query []string false "Content key equals a value, path:value (e.g. status:error); repeatable" collectionFormat(multi)
+// @Param json_has query []string false "Content key exists, e.g. nested_obj_1; repeatable" collectionFormat(multi)
+// @Param json_range query []string false "Numeric comparison on a content key, e.g. duration>1000 (>, >=, <, <=); repeatable" collectionFormat(multi)
+// @Param jsonpath query string false "Raw jsonpath predicate on content, e.g. $.status == \"error\" && $.duration > 4000"
+// @Param page query int false "Page number, at most 1000000" default(1)
+// @Param limit query int false "Items per page, at most 1000" default(50)
+// @Param cursor query string false "Keyset pagination cursor (next_cursor of the previous page); send it empty for the first page. Replaces page"
+// @Param count query string false "Total count mode (default exact, or none with cursor)" Enums(exact, estimated, capped, none)
 // @Success 200 {object} map[string]interface{}
 // @Router /search/partial [get]
 func (h *Handler) SearchLogsPartial(c *gin.Context) {
@@ -298,6 +337,10 @@ func (h *Handler) SearchLogsPartial(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "search_term is required"})
 		return
 	}
+	if filter.Sort == SortRelevance {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort=relevance needs full-text search; use /logs with content_like"})
+		return
+	}
 
 	ctx := context.Background()
 
@@ -305,49 +348,27 @@ func (h *Handler) SearchLogsPartial(c *gin.Context) {
 	queryStart := time.Now()
 
 	// Build filter parameters
-	var userID pgtype.UUID
-	var domain pgtype.Text
-	var createdAtFrom pgtype.Timestamptz
-	var createdAtTo pgtype.Timestamptz
-	var searchTerm pgtype.Text
-
-	if filter.UserID != nil && *filter.UserID != "" {
-		parsedUUID, err := uuid.Parse(*filter.UserID)
-		if err == nil {
-			userID = pgtype.UUID{Bytes: parsedUUID, Valid: true}
-		}
-	}
-
-	if filter.Domain != nil && *filter.Domain != "" {
-		domain = pgtype.Text{String: *filter.Domain, Valid: true}
+	params, err := parseLogFilter(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	if filter.CreatedAt != nil && *filter.CreatedAt != "" {
-		t, err := time.Parse("2006-01-02", *filter.CreatedAt)
-		if err == nil {
-			createdAtFrom = pgtype.Timestamptz{Time: t, Valid: true}
-		}
+	if !h.checkJSONPath(ctx, c, params) {
+		return
 	}
 
-	if filter.CreatedAtTo != nil && *filter.CreatedAtTo != "" {
-		t, err := time.Parse("2006-01-02", *filter.CreatedAtTo)
-		if err == nil {
-			// Set to end of day
-			t = t.Add(24*time.Hour - time.Second)
-			createdAtTo = pgtype.Timestamptz{Time: t, Valid: true}
-		}
+	// A cursor switches to keyset pagination, which skips the count by default
+	if filter.Cursor != nil {
+		h.searchLogsPartialKeyset(c, filter, params, queryStart)
+		return
 	}
 
-	searchTerm = pgtype.Text{String: *filter.SearchTerm, Valid: true}
-
 	// Count total records
-	total, err := h.queries.CountLogsPartial(ctx, db.CountLogsPartialParams{
-		UserID:        userID,
-		Domain:        domain,
-		CreatedAtFrom: createdAtFrom,
-		CreatedAtTo:   createdAtTo,
-		SearchTerm:    searchTerm,
-	})
+	countMode := filter.Count
+	if countMode == "" {
+		countMode = CountExact
+	}
+	total, err := h.count(ctx, countMode, params.partialCounter())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count records"})
 		return
@@ -359,11 +380,15 @@ func (h *Handler) SearchLogsPartial(c *gin.Context) {
 
 	// Fetch logs
 	logs, err := h.queries.SearchLogsPartial(ctx, db.SearchLogsPartialParams{
-		UserID:        userID,
-		Domain:        domain,
-		CreatedAtFrom: createdAtFrom,
-		CreatedAtTo:   createdAtTo,
-		SearchTerm:    searchTerm,
+		UserID:        params.UserID,
+		Domain:        params.Domain,
+		CreatedAtFrom: params.CreatedAtFrom,
+		CreatedAtTo:   params.CreatedAtTo,
+		SearchTerm:    params.SearchTerm,
+		FieldPath:     params.FieldPath,
+		JsonContains:  params.JSONContains,
+		JsonMatch:     params.JSONMatch,
+		JsonPath:      params.JSONPath,
 		Limit:         pgtype.Int4{Int32: limit, Valid: true},
 		Offset:        pgtype.Int4{Int32: offset, Valid: true},
 	})
@@ -377,53 +402,32 @@ func (h *Handler) SearchLogsPartial(c *gin.Context) {
 	// Convert to response format
 	response := make([]map[string]interface{}, len(logs))
 	for i, log := range logs {
-		var content map[string]interface{}
-		if err := json.Unmarshal(log.Content, &content); err != nil {
-			content = map[string]interface{}{"raw": string(log.Content)}
-		}
-
-		response[i] = map[string]interface{}{
-			"id":         uuidToString(log.ID),
-			"user_id":    uuidToString(log.UserID),
-			"domain":     log.Domain,
-			"action":     log.Action,
-			"content":    content,
-			"created_at": log.CreatedAt.Time,
-		}
+		response[i] = logResponse(log.ID, log.UserID, log.Domain, log.Action, log.Content, log.CreatedAt)
 	}
 
-	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
-
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withTotal(gin.H{
 		"data":           response,
-		"total":          total,
 		"page":           filter.Page,
 		"limit":          filter.Limit,
-		"total_pages":    totalPages,
 		"query_duration": queryDuration.String(),
-	})
+	}, countMode, total, filter.Limit))
 }
 
-// Helper functions
-func getRandomDomain() string {
-	domains := []string{
-		"example.com", "test.org", "demo.net", "app.io", "api.service.com",
-		"web.portal.com", "mobile.app.net", "admin.system.org", "user.platform.io",
-		"data.analytics.com", "payments.service.net", "content.media.org", "social.platform.io",
+// logResponse converts a logs row to its JSON representation.
+func logResponse(id, userID pgtype.UUID, domain, action string, rawContent []byte, createdAt pgtype.Timestamptz) map[string]interface{} {
+	var content map[string]interface{}
+	if err := json.Unmarshal(rawContent, &content); err != nil {
+		content = map[string]interface{}{"raw": string(rawContent)}
 	}
-	return domains[rand.Intn(len(domains))]
-}
 
-func getRandomAction() string {
-	actions := []string{
-		"user_login", "user_logout", "page_view", "button_click", "form_submit",
-		"file_upload", "file_download", "search_query", "filter_apply", "sort_change",
-		"create_record", "update_record", "delete_record", "export_data", "import_data",
-		"send_message", "receive_message", "share_content", "like_post", "comment_post",
-		"subscribe", "unsubscribe", "follow_user", "unfollow_user", "report_issue",
-		"request_feature", "update_settings", "change_password", "reset_password",
+	return map[string]interface{}{
+		"id":         uuidToString(id),
+		"user_id":    uuidToString(userID),
+		"domain":     domain,
+		"action":     action,
+		"content":    content,
+		"created_at": createdAt.Time,
 	}
-	return actions[rand.Intn(len(actions))]
 }
 
 func uuidToString(u pgtype.UUID) string {
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"log-project/seeder"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// JobStatus is the lifecycle state of a background seeding job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// maxFinishedJobs bounds how many completed, failed or cancelled jobs are kept
+// around for status queries.
+const maxFinishedJobs = 50
+
+// Job tracks one asynchronous InitializeData run.
+type Job struct {
+	mu sync.Mutex
+
+	ID          string
+	Status      JobStatus
+	RecordCount int
+	ContentSize string
+	Seed        int64
+	Progress    seeder.Progress
+	Result      seeder.Result
+	Error       string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+
+	cancel context.CancelFunc
+}
+
+func (j *Job) update(p seeder.Progress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Progress = p
+}
+
+func (j *Job) finish(result seeder.Result, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Result = result
+	j.Seed = result.Seed
+	j.FinishedAt = time.Now()
+	switch {
+	case err == nil:
+		j.Status = JobCompleted
+	case errors.Is(err, context.Canceled):
+		j.Status = JobCancelled
+	default:
+		j.Status = JobFailed
+		j.Error = err.Error()
+	}
+}
+
+// snapshot renders the job as an API response. Rate and ETA are derived from
+// the rows inserted so far and the time elapsed since the job started.
+func (j *Job) snapshot() gin.H {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	end := time.Now()
+	if !j.FinishedAt.IsZero() {
+		end = j.FinishedAt
+	}
+	elapsed := end.Sub(j.StartedAt)
+
+	inserted := j.Progress.Inserted
+	if j.Status != JobRunning {
+		inserted = j.Result.Inserted
+	}
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(inserted) / elapsed.Seconds()
+	}
+	percent := 0.0
+	if j.RecordCount > 0 {
+		percent = float64(inserted) / float64(j.RecordCount) * 100
+	}
+
+	resp := gin.H{
+		"id":                 j.ID,
+		"status":             j.Status,
+		"record_count":       j.RecordCount,
+		"content_size":       j.ContentSize,
+		"rows_inserted":      inserted,
+		"batch":              j.Progress.Batch,
+		"total_batches":      j.Progress.TotalBatches,
+		"percent":            percent,
+		"records_per_second": rate,
+		"elapsed":            elapsed.Round(time.Millisecond).String(),
+		"started_at":         j.StartedAt,
+	}
+	if j.Seed != 0 {
+		resp["seed"] = j.Seed
+	}
+	if j.Status == JobRunning {
+		eta := "unknown"
+		if rate > 0 {
+			remaining := float64(int64(j.RecordCount) - inserted)
+			eta = (time.Duration(remaining / rate * float64(time.Second))).Round(time.Second).String()
+		}
+		resp["eta"] = eta
+	} else {
+		resp["finished_at"] = j.FinishedAt
+		resp["duration"] = j.Result.Duration.String()
+		resp["stages"] = gin.H{
+			"generate": stageSummary(j.Result.Generate, j.Result.Duration),
+			"write":    stageSummary(j.Result.Write, j.Result.Duration),
+		}
+	}
+	if j.Error != "" {
+		resp["error"] = j.Error
+	}
+	return resp
+}
+
+func stageSummary(s seeder.StageStats, wall time.Duration) gin.H {
+	return gin.H{
+		"workers":            s.Workers,
+		"rows":               s.Rows,
+		"busy":               s.Busy.Round(time.Millisecond).String(),
+		"records_per_second": s.RecordsPerSecond(),
+		"utilization":        s.Utilization(wall),
+	}
+}
+
+// jobManager keeps seeding jobs in memory. Only one job may run at a time
+// since concurrent COPY loads would just compete for the same table.
+type jobManager struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running *Job
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{jobs: make(map[string]*Job)}
+}
+
+// start registers a new job and runs fn in the background with a cancellable
+// context. It returns the currently running job instead if there is one.
+func (m *jobManager) start(job *Job, fn func(ctx context.Context, job *Job) (seeder.Result, error)) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running != nil {
+		return m.running, false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job.ID = uuid.New().String()
+	job.Status = JobRunning
+	job.StartedAt = time.Now()
+	job.cancel = cancel
+
+	m.prune()
+	m.jobs[job.ID] = job
+	m.running = job
+
+	go func() {
+		defer cancel()
+		result, err := fn(ctx, job)
+		job.finish(result, err)
+
+		m.mu.Lock()
+		if m.running == job {
+			m.running = nil
+		}
+		m.mu.Unlock()
+	}()
+
+	return job, true
+}
+
+func (m *jobManager) get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+func (m *jobManager) list() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt.After(jobs[k].StartedAt)
+	})
+	return jobs
+}
+
+// prune drops the oldest finished jobs once more than maxFinishedJobs are
+// retained. Callers must hold m.mu.
+func (m *jobManager) prune() {
+	var finished []*Job
+	for _, job := range m.jobs {
+		if job != m.running {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) < maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, k int) bool {
+		return finished[i].StartedAt.Before(finished[k].StartedAt)
+	})
+	for _, job := range finished[:len(finished)-maxFinishedJobs+1] {
+		delete(m.jobs, job.ID)
+	}
+}
+
+// ListJobs godoc
+// @Summary List seeding jobs
+// @Description List background initialization jobs, newest first
+// @Tags initialization
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	jobs := h.jobs.list()
+	data := make([]gin.H, len(jobs))
+	for i, job := range jobs {
+		data[i] = job.snapshot()
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// GetJob godoc
+// @Summary Get seeding job status
+// @Description Report rows inserted, current batch, insert rate and ETA of an initialization job
+// @Tags initialization
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	job, ok := h.jobs.get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job.snapshot())
+}
+
+// CancelJob godoc
+// @Summary Cancel a seeding job
+// @Description Cancel a running initialization job. Batches already copied stay in the table
+// @Tags initialization
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /jobs/{id} [delete]
+func (h *Handler) CancelJob(c *gin.Context) {
+	job, ok := h.jobs.get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	job.mu.Lock()
+	status := job.Status
+	job.mu.Unlock()
+	if status != JobRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Job is not running", "status": status})
+		return
+	}
+
+	job.cancel()
+	c.JSON(http.StatusAccepted, gin.H{"message": "Cancellation requested", "id": job.ID})
+}
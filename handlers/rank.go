@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"log-project/internal/db"
+	"log-project/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sort orders accepted by GetLogs.
+const (
+	SortCreatedAt = "created_at"
+	SortRelevance = "relevance"
+)
+
+// Ranking functions for SortRelevance.
+const (
+	RankTS   = "ts_rank"    // term frequency
+	RankTSCD = "ts_rank_cd" // cover density: also rewards matched terms that are close together
+)
+
+// listLogsRanked serves GetLogs with sort=relevance. Rows carry their rank
+// and a ts_headline snippet with matches wrapped in <mark>.
+func (h *Handler) listLogsRanked(c *gin.Context, filter models.LogFilter, params logFilterParams, queryStart time.Time) {
+	if filter.TSConfig != "" && filter.TSConfig != TSConfigEnglish {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort=relevance only supports ts_config=english"})
+		return
+	}
+	if !params.ContentSearch.Valid && !params.ContentQuery.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort=relevance requires content_like"})
+		return
+	}
+	if filter.Cursor != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cursor pagination is not supported with sort=relevance; use page"})
+		return
+	}
+	rankFn := filter.RankFn
+	if rankFn == "" {
+		rankFn = RankTS
+	}
+
+	ctx := context.Background()
+
+	countMode := filter.Count
+	if countMode == "" {
+		countMode = CountExact
+	}
+	total, err := h.count(ctx, countMode, params.ftsCounter())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count records"})
+		return
+	}
+
+	logs, err := h.queries.SearchLogsRanked(ctx, db.SearchLogsRankedParams{
+		UserID:        params.UserID,
+		Domain:        params.Domain,
+		CreatedAtFrom: params.CreatedAtFrom,
+		CreatedAtTo:   params.CreatedAtTo,
+		ContentSearch: params.ContentSearch,
+		ContentQuery:  params.ContentQuery,
+		FieldPath:     params.FieldPath,
+		JsonContains:  params.JSONContains,
+		JsonMatch:     params.JSONMatch,
+		JsonPath:      params.JSONPath,
+		RankCd:        rankFn == RankTSCD,
+		Limit:         int32(filter.Limit),
+		Offset:        int32((filter.Page - 1) * filter.Limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query logs"})
+		return
+	}
+
+	queryDuration := time.Since(queryStart)
+
+	response := make([]map[string]interface{}, len(logs))
+	for i, log := range logs {
+		row := logResponse(log.ID, log.UserID, log.Domain, log.Action, log.Content, log.CreatedAt)
+		row["rank"] = log.Rank
+		row["headline"] = log.Headline
+		response[i] = row
+	}
+
+	c.JSON(http.StatusOK, withTotal(gin.H{
+		"data":           response,
+		"page":           filter.Page,
+		"limit":          filter.Limit,
+		"sort":           SortRelevance,
+		"rank_fn":        rankFn,
+		"query_duration": queryDuration.String(),
+	}, countMode, total, filter.Limit))
+}
@@ -0,0 +1,338 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"log-project/internal/db"
+
+	"github.com/google/uuid"
+)
+
+// Input formats understood by Load.
+const (
+	FormatJSONL  = "jsonl"
+	FormatCSV    = "csv"
+	FormatNginx  = "nginx"
+	FormatApache = "apache"
+)
+
+// AccessLogTimeFormat is the $time_local / %t layout of nginx and Apache.
+const AccessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// userNamespace derives stable user IDs from source values that are not
+// UUIDs (an IP address, an account name, ...).
+var userNamespace = uuid.MustParse("6f1b2a8e-3c4d-4e5f-8a9b-0c1d2e3f4a5b")
+
+// Mapping says which source fields fill the logs columns. Every field that is
+// not mapped ends up in content. An empty field name, or a field missing from
+// a record, falls back to the matching default.
+type Mapping struct {
+	UserID    string
+	Domain    string
+	Action    string
+	CreatedAt string
+
+	DefaultUserID string
+	DefaultDomain string
+	DefaultAction string
+
+	// TimeFormat is the layout of the created_at field. Empty accepts
+	// RFC 3339; numeric values are always read as Unix seconds (or
+	// milliseconds when too large to be seconds).
+	TimeFormat string
+}
+
+// DefaultMapping returns the mapping used for format when none is given.
+func DefaultMapping(format string) Mapping {
+	switch format {
+	case FormatNginx, FormatApache:
+		return Mapping{
+			UserID:        "remote_addr",
+			Action:        "method",
+			CreatedAt:     "time_local",
+			DefaultDomain: "unknown",
+			TimeFormat:    AccessLogTimeFormat,
+		}
+	default:
+		return Mapping{
+			UserID:    "user_id",
+			Domain:    "domain",
+			Action:    "action",
+			CreatedAt: "created_at",
+		}
+	}
+}
+
+// ParseMapping applies "column=field" pairs (comma separated) to m, e.g.
+// "user_id=uid,created_at=ts". Valid columns are user_id, domain, action and
+// created_at.
+func ParseMapping(m Mapping, spec string) (Mapping, error) {
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		column, field, ok := strings.Cut(pair, "=")
+		if !ok {
+			return m, fmt.Errorf("invalid mapping %q (want column=field)", pair)
+		}
+		field = strings.TrimSpace(field)
+		switch strings.TrimSpace(column) {
+		case "user_id":
+			m.UserID = field
+		case "domain":
+			m.Domain = field
+		case "action":
+			m.Action = field
+		case "created_at":
+			m.CreatedAt = field
+		default:
+			return m, fmt.Errorf("unknown column %q in mapping (want user_id, domain, action or created_at)", column)
+		}
+	}
+	return m, nil
+}
+
+// Record builds a Record from a parsed source line. fields is consumed: the
+// mapped entries are removed and the rest become the content.
+func (m Mapping) Record(fields map[string]interface{}, now time.Time) (Record, error) {
+	rec := Record{
+		UserID: m.DefaultUserID,
+		Domain: m.DefaultDomain,
+		Action: m.DefaultAction,
+	}
+
+	if v, ok := take(fields, m.UserID); ok {
+		rec.UserID = v
+	}
+	if rec.UserID != "" {
+		if _, err := uuid.Parse(rec.UserID); err != nil {
+			rec.UserID = uuid.NewSHA1(userNamespace, []byte(rec.UserID)).String()
+		}
+	}
+	if v, ok := take(fields, m.Domain); ok {
+		rec.Domain = v
+	}
+	if v, ok := take(fields, m.Action); ok {
+		rec.Action = v
+	}
+
+	if m.CreatedAt != "" {
+		if raw, ok := fields[m.CreatedAt]; ok && raw != nil {
+			delete(fields, m.CreatedAt)
+			t, err := parseTime(raw, m.TimeFormat)
+			if err != nil {
+				return rec, fmt.Errorf("created_at: %w", err)
+			}
+			rec.CreatedAt = &t
+		}
+	}
+
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return rec, fmt.Errorf("failed to marshal content: %w", err)
+	}
+	rec.Content = content
+	return rec, nil
+}
+
+// take removes fields[name] and returns it as a string.
+func take(fields map[string]interface{}, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	v, ok := fields[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	delete(fields, name)
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func parseTime(v interface{}, layout string) (time.Time, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(int64(n)), nil
+		}
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)), nil
+	}
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return time.Parse(layout, s)
+}
+
+// Load imports r in the given format through loader. Records are mapped
+// with m; see LoadNDJSON for how errors are reported.
+func Load(ctx context.Context, r io.Reader, format string, m Mapping, loader *Loader) (Summary, error) {
+	now := time.Now()
+
+	switch format {
+	case FormatJSONL:
+		return loadLines(ctx, r, loader, func(line []byte) (db.BulkInsertLogsParams, error) {
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			var fields map[string]interface{}
+			if err := dec.Decode(&fields); err != nil {
+				return db.BulkInsertLogsParams{}, fmt.Errorf("invalid JSON: %w", err)
+			}
+			if dec.More() {
+				return db.BulkInsertLogsParams{}, errors.New("invalid JSON: unexpected data after the record")
+			}
+			if fields == nil {
+				return db.BulkInsertLogsParams{}, errors.New("record must be a JSON object")
+			}
+			return m.params(fields, now)
+		})
+	case FormatNginx, FormatApache:
+		return loadLines(ctx, r, loader, func(line []byte) (db.BulkInsertLogsParams, error) {
+			fields, err := parseAccessLog(string(line))
+			if err != nil {
+				return db.BulkInsertLogsParams{}, err
+			}
+			return m.params(fields, now)
+		})
+	case FormatCSV:
+		return loadCSV(ctx, r, m, loader, now)
+	default:
+		return Summary{}, fmt.Errorf("unknown format %q (want jsonl, csv, nginx or apache)", format)
+	}
+}
+
+func (m Mapping) params(fields map[string]interface{}, now time.Time) (db.BulkInsertLogsParams, error) {
+	rec, err := m.Record(fields, now)
+	if err != nil {
+		return db.BulkInsertLogsParams{}, err
+	}
+	return rec.Params(now)
+}
+
+// loadCSV imports a CSV file whose first row names the fields.
+func loadCSV(ctx context.Context, r io.Reader, m Mapping, loader *Loader, now time.Time) (Summary, error) {
+	start := time.Now()
+	summary := Summary{Errors: []LineError{}}
+	finish := func(err error) (Summary, error) {
+		summary.Inserted = loader.Inserted
+		summary.Bytes = loader.Bytes
+		summary.Duration = time.Since(start)
+		return summary, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return finish(nil)
+		}
+		return finish(fmt.Errorf("failed to read CSV header: %w", err))
+	}
+	header = append([]string(nil), header...)
+	summary.Lines = 1
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		summary.Lines = line
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			summary.reject(parseErr.StartLine, parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return finish(fmt.Errorf("failed to read input: %w", err))
+		}
+		if len(row) != len(header) {
+			summary.reject(line, fmt.Errorf("expected %d fields, got %d", len(header), len(row)))
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			fields[name] = row[i]
+		}
+		params, err := m.params(fields, now)
+		if err != nil {
+			summary.reject(line, err)
+			continue
+		}
+		if err := loader.Add(ctx, line, params); err != nil {
+			return finish(err)
+		}
+	}
+
+	if err := loader.Flush(ctx); err != nil {
+		return finish(err)
+	}
+	return finish(nil)
+}
+
+// accessLogPattern matches the Common and Combined Log Formats shared by
+// nginx's default "combined" log_format and Apache's %h %l %u %t "%r" %>s %b
+// ["%{Referer}i" "%{User-agent}i"].
+var accessLogPattern = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\S+)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`)
+
+func parseAccessLog(line string) (map[string]interface{}, error) {
+	m := accessLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, errors.New("line does not match the common/combined access log format")
+	}
+
+	fields := map[string]interface{}{
+		"remote_addr": m[1],
+		"time_local":  m[4],
+		"request":     m[5],
+	}
+	if m[3] != "-" {
+		fields["remote_user"] = m[3]
+	}
+	if parts := strings.SplitN(m[5], " ", 3); len(parts) == 3 {
+		fields["method"] = parts[0]
+		fields["path"] = parts[1]
+		fields["protocol"] = parts[2]
+	}
+	status, _ := strconv.Atoi(m[6])
+	fields["status"] = status
+	if n, err := strconv.ParseInt(m[7], 10, 64); err == nil {
+		fields["body_bytes_sent"] = n
+	}
+	if m[8] != "" && m[8] != "-" {
+		fields["http_referer"] = m[8]
+	}
+	if m[9] != "" && m[9] != "-" {
+		fields["http_user_agent"] = m[9]
+	}
+	return fields, nil
+}
@@ -0,0 +1,208 @@
+// Package importer loads externally produced log records into the logs table
+// through COPY FROM, validating every record on the way in.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"log-project/internal/db"
+	"log-project/search"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultBatchSize is the number of records sent per COPY FROM round-trip.
+const DefaultBatchSize = 1000
+
+// maxTextLength matches the VARCHAR(255) domain and action columns.
+const maxTextLength = 255
+
+// Record is one log entry as accepted by the importer. CreatedAt is optional
+// and defaults to the time of the import.
+type Record struct {
+	UserID    string          `json:"user_id"`
+	Domain    string          `json:"domain"`
+	Action    string          `json:"action"`
+	Content   json.RawMessage `json:"content"`
+	CreatedAt *time.Time      `json:"created_at"`
+}
+
+// Params validates r and converts it to a BulkInsertLogs row. now is used
+// when the record has no created_at.
+func (r Record) Params(now time.Time) (db.BulkInsertLogsParams, error) {
+	userID, err := uuid.Parse(r.UserID)
+	if err != nil {
+		if r.UserID == "" {
+			return db.BulkInsertLogsParams{}, errors.New("user_id is required")
+		}
+		return db.BulkInsertLogsParams{}, fmt.Errorf("user_id: %w", err)
+	}
+	if err := validateText("domain", r.Domain); err != nil {
+		return db.BulkInsertLogsParams{}, err
+	}
+	if err := validateText("action", r.Action); err != nil {
+		return db.BulkInsertLogsParams{}, err
+	}
+
+	content := bytes.TrimSpace(r.Content)
+	if len(content) == 0 || bytes.Equal(content, []byte("null")) {
+		return db.BulkInsertLogsParams{}, errors.New("content is required")
+	}
+	if content[0] != '{' {
+		return db.BulkInsertLogsParams{}, errors.New("content must be a JSON object")
+	}
+	if err := validateContent(content); err != nil {
+		return db.BulkInsertLogsParams{}, err
+	}
+
+	createdAt := now
+	if r.CreatedAt != nil {
+		createdAt = *r.CreatedAt
+	}
+
+	return db.BulkInsertLogsParams{
+		UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+		Domain:     r.Domain,
+		Action:     r.Action,
+		Content:    content,
+		ContentCjk: search.ContentCJK(content),
+		CreatedAt:  pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}, nil
+}
+
+// validateContent rejects JSON that encoding/json accepts but jsonb does not:
+// invalid UTF-8, \u0000 and unpaired UTF-16 surrogate escapes. One such row
+// would fail its whole COPY batch, so it is rejected on its own here. content
+// must already be well-formed JSON.
+func validateContent(content []byte) error {
+	if !utf8.Valid(content) {
+		return errors.New("content is not valid UTF-8")
+	}
+	inString := false
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '"':
+			inString = !inString
+		case c == '\\' && inString:
+			i++
+			if content[i] != 'u' {
+				continue
+			}
+			r := escapedRune(content[i+1 : i+5])
+			i += 4
+			switch {
+			case r == 0:
+				return errors.New(`content must not contain \u0000`)
+			case utf16.IsSurrogate(r):
+				// A high surrogate must be followed by a low one
+				if r >= 0xDC00 || i+6 >= len(content) || content[i+1] != '\\' || content[i+2] != 'u' ||
+					utf16.DecodeRune(r, escapedRune(content[i+3:i+7])) == unicode.ReplacementChar {
+					return fmt.Errorf(`content has an unpaired surrogate \u%s`, content[i-3:i+1])
+				}
+				i += 6
+			}
+		}
+	}
+	return nil
+}
+
+// escapedRune decodes the four hex digits of a \u escape.
+func escapedRune(hex []byte) rune {
+	var r rune
+	for _, c := range hex {
+		r <<= 4
+		switch {
+		case c >= '0' && c <= '9':
+			r |= rune(c - '0')
+		case c >= 'a' && c <= 'f':
+			r |= rune(c - 'a' + 10)
+		case c >= 'A' && c <= 'F':
+			r |= rune(c - 'A' + 10)
+		}
+	}
+	return r
+}
+
+func validateText(field, s string) error {
+	switch {
+	case s == "":
+		return fmt.Errorf("%s is required", field)
+	case !utf8.ValidString(s):
+		return fmt.Errorf("%s is not valid UTF-8", field)
+	case strings.ContainsRune(s, 0):
+		return fmt.Errorf("%s must not contain NUL characters", field)
+	case utf8.RuneCountInString(s) > maxTextLength:
+		return fmt.Errorf("%s is longer than %d characters", field, maxTextLength)
+	}
+	return nil
+}
+
+// LineError reports why one input line was rejected.
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Loader buffers validated rows and COPYs them in batches.
+type Loader struct {
+	queries   *db.Queries
+	batchSize int
+	batch     []db.BulkInsertLogsParams
+	firstLine int // Input line of batch[0], for error messages
+
+	Inserted int64
+	Bytes    int64 // Total size of the content copied
+}
+
+// NewLoader returns a Loader writing through queries. batchSize <= 0 uses
+// DefaultBatchSize.
+func NewLoader(queries *db.Queries, batchSize int) *Loader {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Loader{
+		queries:   queries,
+		batchSize: batchSize,
+		batch:     make([]db.BulkInsertLogsParams, 0, batchSize),
+	}
+}
+
+// Add queues a row read from the given input line and flushes the batch once
+// it is full.
+func (l *Loader) Add(ctx context.Context, line int, p db.BulkInsertLogsParams) error {
+	if len(l.batch) == 0 {
+		l.firstLine = line
+	}
+	l.batch = append(l.batch, p)
+	if len(l.batch) >= l.batchSize {
+		return l.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush copies any queued rows.
+func (l *Loader) Flush(ctx context.Context) error {
+	if len(l.batch) == 0 {
+		return nil
+	}
+	n, err := l.queries.BulkInsertLogs(ctx, l.batch)
+	if err != nil {
+		return fmt.Errorf("failed to insert batch starting at line %d: %w", l.firstLine, err)
+	}
+	l.Inserted += n
+	for _, p := range l.batch {
+		l.Bytes += int64(len(p.Content))
+	}
+	l.batch = l.batch[:0]
+	return nil
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeLineContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "plain", content: `{"message":"login failed"}`},
+		{name: "multi-byte", content: `{"message":"ログイン失敗"}`},
+		{name: "escaped backslash before u0000", content: `{"path":"C:\\u0000"}`},
+		{name: "escaped quote", content: `{"message":"say \"\u0000\""}`, wantErr: `\u0000`},
+		{name: "surrogate pair", content: `{"emoji":"\ud83d\ude00"}`},
+		{name: "surrogate pair upper case", content: `{"emoji":"\uD83D\uDE00"}`},
+		{name: "nul escape", content: `{"message":"a\u0000b"}`, wantErr: `must not contain \u0000`},
+		{name: "nul escape in key", content: `{"a\u0000":1}`, wantErr: `must not contain \u0000`},
+		{name: "lone high surrogate", content: `{"message":"\ud800"}`, wantErr: `unpaired surrogate \ud800`},
+		{name: "lone high surrogate then text", content: `{"message":"\ud800abcdefg"}`, wantErr: "unpaired surrogate"},
+		{name: "high surrogate then non-surrogate", content: `{"message":"\ud800\u0041"}`, wantErr: "unpaired surrogate"},
+		{name: "lone low surrogate", content: `{"message":"\udc00"}`, wantErr: `unpaired surrogate \udc00`},
+		{name: "two high surrogates", content: `{"message":"\ud800\ud800"}`, wantErr: "unpaired surrogate"},
+		{name: "invalid utf-8", content: "{\"message\":\"\xff\xfe\"}", wantErr: "not valid UTF-8"},
+		{name: "truncated utf-8", content: "{\"message\":\"\xe3\x83\"}", wantErr: "not valid UTF-8"},
+	}
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := `{"user_id":"0b6f7c2e-2d5a-4f0e-9a57-3c1d2e4f5a6b","domain":"example.com","action":"user_login","content":` + tt.content + `}`
+			p, err := decodeLine([]byte(line), now)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got %s, want error containing %q", p.Content, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %q does not contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(p.Content) != tt.content {
+				t.Errorf("Content = %s, want %s", p.Content, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"log-project/internal/db"
+)
+
+// MaxLineBytes is the longest input line accepted. Longer lines are rejected
+// without being buffered in full.
+const MaxLineBytes = 16 << 20
+
+// MaxReportedErrors caps how many per-line errors a Summary carries; Rejected
+// still counts all of them.
+const MaxReportedErrors = 1000
+
+// Summary is the outcome of one import.
+type Summary struct {
+	Lines           int           `json:"lines"`
+	Inserted        int64         `json:"inserted"`
+	Rejected        int           `json:"rejected"`
+	Bytes           int64         `json:"bytes"`
+	Errors          []LineError   `json:"errors"`
+	ErrorsTruncated bool          `json:"errors_truncated,omitempty"`
+	Duration        time.Duration `json:"-"`
+}
+
+// RecordsPerSecond returns the overall insert throughput.
+func (s Summary) RecordsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Inserted) / s.Duration.Seconds()
+}
+
+// reject records a line error.
+func (s *Summary) reject(line int, err error) {
+	s.Rejected++
+	if len(s.Errors) < MaxReportedErrors {
+		s.Errors = append(s.Errors, LineError{Line: line, Error: err.Error()})
+	} else {
+		s.ErrorsTruncated = true
+	}
+}
+
+// LoadNDJSON streams newline-delimited JSON records from r into loader. Each
+// line is decoded and validated on its own: bad lines are reported in the
+// Summary and skipped, blank lines are ignored. The returned error is only
+// set for failures that stop the import (reading r or a failed COPY); the
+// Summary then describes what was loaded before it.
+func LoadNDJSON(ctx context.Context, r io.Reader, loader *Loader) (Summary, error) {
+	now := time.Now()
+	return loadLines(ctx, r, loader, func(line []byte) (db.BulkInsertLogsParams, error) {
+		return decodeLine(line, now)
+	})
+}
+
+// loadLines drives a line-oriented import: decode turns one non-blank line
+// into a row or an error that rejects the line.
+func loadLines(ctx context.Context, r io.Reader, loader *Loader, decode func(line []byte) (db.BulkInsertLogsParams, error)) (Summary, error) {
+	start := time.Now()
+	summary := Summary{Errors: []LineError{}}
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	finish := func(err error) (Summary, error) {
+		summary.Inserted = loader.Inserted
+		summary.Bytes = loader.Bytes
+		summary.Duration = time.Since(start)
+		return summary, err
+	}
+
+	for {
+		line, tooLong, err := readLine(reader)
+		if len(line) > 0 || tooLong || err == nil {
+			summary.Lines++
+			lineNo := summary.Lines
+
+			switch {
+			case tooLong:
+				summary.reject(lineNo, fmt.Errorf("line is longer than %d bytes", MaxLineBytes))
+			case len(bytes.TrimSpace(line)) == 0:
+				// Blank line
+			default:
+				params, perr := decode(line)
+				if perr != nil {
+					summary.reject(lineNo, perr)
+					break
+				}
+				if err := loader.Add(ctx, lineNo, params); err != nil {
+					return finish(err)
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return finish(fmt.Errorf("failed to read input: %w", err))
+		}
+	}
+
+	if err := loader.Flush(ctx); err != nil {
+		return finish(err)
+	}
+	return finish(nil)
+}
+
+func decodeLine(line []byte, now time.Time) (db.BulkInsertLogsParams, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+
+	var rec Record
+	if err := dec.Decode(&rec); err != nil {
+		return db.BulkInsertLogsParams{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return db.BulkInsertLogsParams{}, errors.New("invalid JSON: unexpected data after the record")
+	}
+	return rec.Params(now)
+}
+
+// readLine returns the next line without its trailing newline. Lines longer
+// than MaxLineBytes are consumed and discarded, with tooLong set. At the end
+// of input err is io.EOF, possibly alongside a final unterminated line.
+func readLine(r *bufio.Reader) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return line, tooLong, err
+		}
+		if !tooLong {
+			if len(line)+len(chunk) > MaxLineBytes {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if !isPrefix {
+			return line, tooLong, nil
+		}
+	}
+}
@@ -33,6 +33,7 @@ func (r iteratorForBulkInsertLogs) Values() ([]interface{}, error) {
 		r.rows[0].Domain,
 		r.rows[0].Action,
 		r.rows[0].Content,
+		r.rows[0].ContentCjk,
 		r.rows[0].CreatedAt,
 	}, nil
 }
@@ -42,5 +43,5 @@ func (r iteratorForBulkInsertLogs) Err() error {
 }
 
 func (q *Queries) BulkInsertLogs(ctx context.Context, arg []BulkInsertLogsParams) (int64, error) {
-	return q.db.CopyFrom(ctx, []string{"logs"}, []string{"user_id", "domain", "action", "content", "created_at"}, &iteratorForBulkInsertLogs{rows: arg})
+	return q.db.CopyFrom(ctx, []string{"logs"}, []string{"user_id", "domain", "action", "content", "content_cjk", "created_at"}, &iteratorForBulkInsertLogs{rows: arg})
 }
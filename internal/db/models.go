@@ -9,10 +9,13 @@ import (
 )
 
 type Log struct {
-	ID        pgtype.UUID        `json:"id"`
-	UserID    pgtype.UUID        `json:"user_id"`
-	Domain    string             `json:"domain"`
-	Action    string             `json:"action"`
-	Content   []byte             `json:"content"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        string             `json:"domain"`
+	Action        string             `json:"action"`
+	Content       []byte             `json:"content"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ContentTsv    interface{}        `json:"content_tsv"`
+	ContentCjk    pgtype.Text        `json:"content_cjk"`
+	ContentCjkTsv interface{}        `json:"content_cjk_tsv"`
 }
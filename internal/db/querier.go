@@ -14,16 +14,55 @@ type Querier interface {
 	BulkInsertLogs(ctx context.Context, arg []BulkInsertLogsParams) (int64, error)
 	CountLogs(ctx context.Context) (int64, error)
 	CountLogsPartial(ctx context.Context, arg CountLogsPartialParams) (int64, error)
+	// Capped variant of CountLogsPartial; see CountLogsWithFiltersCapped.
+	CountLogsPartialCapped(ctx context.Context, arg CountLogsPartialCappedParams) (int64, error)
 	CountLogsWithFilters(ctx context.Context, arg CountLogsWithFiltersParams) (int64, error)
-	CreateLog(ctx context.Context, arg CreateLogParams) (Log, error)
+	// Counts at most max_count matching rows, so the scan stops early on large
+	// result sets.
+	CountLogsWithFiltersCapped(ctx context.Context, arg CountLogsWithFiltersCappedParams) (int64, error)
+	CreateLog(ctx context.Context, arg CreateLogParams) (CreateLogRow, error)
 	DeleteLog(ctx context.Context, id pgtype.UUID) error
-	GetLog(ctx context.Context, id pgtype.UUID) (Log, error)
-	ListLogs(ctx context.Context, arg ListLogsParams) ([]Log, error)
-	ListLogsByDomain(ctx context.Context, arg ListLogsByDomainParams) ([]Log, error)
-	ListLogsByUserID(ctx context.Context, arg ListLogsByUserIDParams) ([]Log, error)
-	ListLogsWithFilters(ctx context.Context, arg ListLogsWithFiltersParams) ([]Log, error)
-	SearchLogsPartial(ctx context.Context, arg SearchLogsPartialParams) ([]Log, error)
+	// Every row /logs (content_like) and /search/partial (search_term) match for
+	// the filters, without a LIMIT. The export handler declares a server-side
+	// cursor over this query's SQL rather than calling it.
+	ExportLogs(ctx context.Context, arg ExportLogsParams) ([]ExportLogsRow, error)
+	GetFTSStorageStats(ctx context.Context) (GetFTSStorageStatsRow, error)
+	GetLog(ctx context.Context, id pgtype.UUID) (GetLogRow, error)
+	// One batch of rows in id order, starting after after_id (or at the first row
+	// when it is NULL), for jobs that walk the whole table.
+	ListLogContentAfter(ctx context.Context, arg ListLogContentAfterParams) ([]ListLogContentAfterRow, error)
+	ListLogs(ctx context.Context, arg ListLogsParams) ([]ListLogsRow, error)
+	ListLogsByDomain(ctx context.Context, arg ListLogsByDomainParams) ([]ListLogsByDomainRow, error)
+	ListLogsByUserID(ctx context.Context, arg ListLogsByUserIDParams) ([]ListLogsByUserIDRow, error)
+	// Filters go through log_matches (migration 00006), shared with the other
+	// list, count and export queries; NULL arguments disable their filter.
+	// field_path (a jsonpath from search.ParseField) limits content matches to the
+	// values it selects. It is checked on the rows the content_tsv, simple or
+	// content_cjk_tsv predicates already matched through their indexes.
+	ListLogsWithFilters(ctx context.Context, arg ListLogsWithFiltersParams) ([]ListLogsWithFiltersRow, error)
+	// Same as ListLogsWithFilters but matches against the idx_logs_content_fts
+	// expression index instead of the stored content_tsv column.
+	ListLogsWithFiltersExpression(ctx context.Context, arg ListLogsWithFiltersExpressionParams) ([]ListLogsWithFiltersExpressionRow, error)
+	// Keyset pagination over (created_at, id): returns the rows that come after
+	// the (after_created_at, after_id) cursor in created_at DESC, id DESC order,
+	// or the first page when the cursor is NULL.
+	ListLogsWithFiltersKeyset(ctx context.Context, arg ListLogsWithFiltersKeysetParams) ([]ListLogsWithFiltersKeysetRow, error)
+	// field_path limits matches to the values it selects, as in
+	// ListLogsWithFilters; the trigram index still finds the candidate rows.
+	SearchLogsPartial(ctx context.Context, arg SearchLogsPartialParams) ([]SearchLogsPartialRow, error)
+	// Keyset variant of SearchLogsPartial; see ListLogsWithFiltersKeyset.
+	SearchLogsPartialKeyset(ctx context.Context, arg SearchLogsPartialKeysetParams) ([]SearchLogsPartialKeysetRow, error)
+	// Full-text matches ordered by relevance, with ts_rank_cd (cover density)
+	// instead of ts_rank when rank_cd is set. The query is content_query (tsquery
+	// syntax) when given, else content_search (plain text). ts_headline re-parses
+	// the document, so it only runs on the rows of the requested page.
+	SearchLogsRanked(ctx context.Context, arg SearchLogsRankedParams) ([]SearchLogsRankedRow, error)
 	TruncateLogs(ctx context.Context) error
+	// Sets content_cjk for a batch of rows; an empty string stores NULL. Rows
+	// that already hold the value are not rewritten.
+	UpdateContentCJK(ctx context.Context, arg UpdateContentCJKParams) (int64, error)
+	// Fails with a syntax error when path is not a valid jsonpath.
+	ValidateJSONPath(ctx context.Context, path string) error
 }
 
 var _ Querier = (*Queries)(nil)
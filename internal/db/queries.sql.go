@@ -12,11 +12,12 @@ import (
 )
 
 type BulkInsertLogsParams struct {
-	UserID    pgtype.UUID        `json:"user_id"`
-	Domain    string             `json:"domain"`
-	Action    string             `json:"action"`
-	Content   []byte             `json:"content"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Domain     string             `json:"domain"`
+	Action     string             `json:"action"`
+	Content    []byte             `json:"content"`
+	ContentCjk pgtype.Text        `json:"content_cjk"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 const countLogs = `-- name: CountLogs :one
@@ -32,12 +33,12 @@ func (q *Queries) CountLogs(ctx context.Context) (int64, error) {
 
 const countLogsPartial = `-- name: CountLogsPartial :one
 SELECT COUNT(*) FROM logs
-WHERE 
-    ($1::uuid IS NULL OR user_id = $1) AND
-    ($2::text IS NULL OR domain = $2) AND
-    ($3::timestamptz IS NULL OR created_at >= $3) AND
-    ($4::timestamptz IS NULL OR created_at <= $4) AND
-    content::text ILIKE '%' || $5::text || '%'
+WHERE
+    log_matches(logs,
+        $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        $5::text, $6::text,
+        $7::jsonb, $8::text, $9::text)
 `
 
 type CountLogsPartialParams struct {
@@ -46,6 +47,10 @@ type CountLogsPartialParams struct {
 	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
 	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
 	SearchTerm    pgtype.Text        `json:"search_term"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
 }
 
 func (q *Queries) CountLogsPartial(ctx context.Context, arg CountLogsPartialParams) (int64, error) {
@@ -55,6 +60,55 @@ func (q *Queries) CountLogsPartial(ctx context.Context, arg CountLogsPartialPara
 		arg.CreatedAtFrom,
 		arg.CreatedAtTo,
 		arg.SearchTerm,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countLogsPartialCapped = `-- name: CountLogsPartialCapped :one
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM logs
+    WHERE
+        log_matches(logs,
+            $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+            NULL::text, NULL::text, NULL::text, NULL::text,
+            $5::text, $6::text,
+            $7::jsonb, $8::text, $9::text)
+    LIMIT $10
+) capped
+`
+
+type CountLogsPartialCappedParams struct {
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        pgtype.Text        `json:"domain"`
+	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
+	SearchTerm    pgtype.Text        `json:"search_term"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
+	MaxCount      int32              `json:"max_count"`
+}
+
+// Capped variant of CountLogsPartial; see CountLogsWithFiltersCapped.
+func (q *Queries) CountLogsPartialCapped(ctx context.Context, arg CountLogsPartialCappedParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countLogsPartialCapped,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.SearchTerm,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+		arg.MaxCount,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -63,12 +117,12 @@ func (q *Queries) CountLogsPartial(ctx context.Context, arg CountLogsPartialPara
 
 const countLogsWithFilters = `-- name: CountLogsWithFilters :one
 SELECT COUNT(*) FROM logs
-WHERE 
-    ($1::uuid IS NULL OR user_id = $1) AND
-    ($2::text IS NULL OR domain = $2) AND
-    ($3::timestamptz IS NULL OR created_at >= $3) AND
-    ($4::timestamptz IS NULL OR created_at <= $4) AND
-    ($5::text IS NULL OR to_tsvector('english', content::text) @@ plainto_tsquery('english', $5))
+WHERE
+    log_matches(logs,
+        $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+        $5::text, $6::text, $7::text, $8::text,
+        NULL::text, $9::text,
+        $10::jsonb, $11::text, $12::text)
 `
 
 type CountLogsWithFiltersParams struct {
@@ -77,6 +131,13 @@ type CountLogsWithFiltersParams struct {
 	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
 	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
 	ContentSearch pgtype.Text        `json:"content_search"`
+	ContentQuery  pgtype.Text        `json:"content_query"`
+	SimpleQuery   pgtype.Text        `json:"simple_query"`
+	CjkQuery      pgtype.Text        `json:"cjk_query"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
 }
 
 func (q *Queries) CountLogsWithFilters(ctx context.Context, arg CountLogsWithFiltersParams) (int64, error) {
@@ -86,6 +147,65 @@ func (q *Queries) CountLogsWithFilters(ctx context.Context, arg CountLogsWithFil
 		arg.CreatedAtFrom,
 		arg.CreatedAtTo,
 		arg.ContentSearch,
+		arg.ContentQuery,
+		arg.SimpleQuery,
+		arg.CjkQuery,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countLogsWithFiltersCapped = `-- name: CountLogsWithFiltersCapped :one
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM logs
+    WHERE
+        log_matches(logs,
+            $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+            $5::text, $6::text, $7::text, $8::text,
+            NULL::text, $9::text,
+            $10::jsonb, $11::text, $12::text)
+    LIMIT $13
+) capped
+`
+
+type CountLogsWithFiltersCappedParams struct {
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        pgtype.Text        `json:"domain"`
+	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
+	ContentSearch pgtype.Text        `json:"content_search"`
+	ContentQuery  pgtype.Text        `json:"content_query"`
+	SimpleQuery   pgtype.Text        `json:"simple_query"`
+	CjkQuery      pgtype.Text        `json:"cjk_query"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
+	MaxCount      int32              `json:"max_count"`
+}
+
+// Counts at most max_count matching rows, so the scan stops early on large
+// result sets.
+func (q *Queries) CountLogsWithFiltersCapped(ctx context.Context, arg CountLogsWithFiltersCappedParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countLogsWithFiltersCapped,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.ContentSearch,
+		arg.ContentQuery,
+		arg.SimpleQuery,
+		arg.CjkQuery,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+		arg.MaxCount,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -93,12 +213,22 @@ func (q *Queries) CountLogsWithFilters(ctx context.Context, arg CountLogsWithFil
 }
 
 const createLog = `-- name: CreateLog :one
-INSERT INTO logs (user_id, domain, action, content, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO logs (user_id, domain, action, content, content_cjk, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id, user_id, domain, action, content, created_at
 `
 
 type CreateLogParams struct {
+	UserID     pgtype.UUID        `json:"user_id"`
+	Domain     string             `json:"domain"`
+	Action     string             `json:"action"`
+	Content    []byte             `json:"content"`
+	ContentCjk pgtype.Text        `json:"content_cjk"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type CreateLogRow struct {
+	ID        pgtype.UUID        `json:"id"`
 	UserID    pgtype.UUID        `json:"user_id"`
 	Domain    string             `json:"domain"`
 	Action    string             `json:"action"`
@@ -106,15 +236,16 @@ type CreateLogParams struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
-func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, error) {
+func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (CreateLogRow, error) {
 	row := q.db.QueryRow(ctx, createLog,
 		arg.UserID,
 		arg.Domain,
 		arg.Action,
 		arg.Content,
+		arg.ContentCjk,
 		arg.CreatedAt,
 	)
-	var i Log
+	var i CreateLogRow
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
@@ -135,15 +266,126 @@ func (q *Queries) DeleteLog(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const exportLogs = `-- name: ExportLogs :many
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+        $5::text, $6::text, $7::text, $8::text,
+        $9::text, $10::text,
+        $11::jsonb, $12::text, $13::text)
+ORDER BY created_at DESC
+`
+
+type ExportLogsParams struct {
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        pgtype.Text        `json:"domain"`
+	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
+	ContentSearch pgtype.Text        `json:"content_search"`
+	ContentQuery  pgtype.Text        `json:"content_query"`
+	SimpleQuery   pgtype.Text        `json:"simple_query"`
+	CjkQuery      pgtype.Text        `json:"cjk_query"`
+	SearchTerm    pgtype.Text        `json:"search_term"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
+}
+
+type ExportLogsRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Every row /logs (content_like) and /search/partial (search_term) match for
+// the filters, without a LIMIT. The export handler declares a server-side
+// cursor over this query's SQL rather than calling it.
+func (q *Queries) ExportLogs(ctx context.Context, arg ExportLogsParams) ([]ExportLogsRow, error) {
+	rows, err := q.db.Query(ctx, exportLogs,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.ContentSearch,
+		arg.ContentQuery,
+		arg.SimpleQuery,
+		arg.CjkQuery,
+		arg.SearchTerm,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExportLogsRow{}
+	for rows.Next() {
+		var i ExportLogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Domain,
+			&i.Action,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFTSStorageStats = `-- name: GetFTSStorageStats :one
+SELECT
+    COALESCE(pg_relation_size(to_regclass('idx_logs_content_fts')), 0)::bigint AS expression_index_bytes,
+    COALESCE(pg_relation_size(to_regclass('idx_logs_content_tsv')), 0)::bigint AS stored_index_bytes,
+    COALESCE(SUM(pg_column_size(content_tsv)), 0)::bigint AS stored_column_bytes
+FROM logs
+`
+
+type GetFTSStorageStatsRow struct {
+	ExpressionIndexBytes int64 `json:"expression_index_bytes"`
+	StoredIndexBytes     int64 `json:"stored_index_bytes"`
+	StoredColumnBytes    int64 `json:"stored_column_bytes"`
+}
+
+func (q *Queries) GetFTSStorageStats(ctx context.Context) (GetFTSStorageStatsRow, error) {
+	row := q.db.QueryRow(ctx, getFTSStorageStats)
+	var i GetFTSStorageStatsRow
+	err := row.Scan(&i.ExpressionIndexBytes, &i.StoredIndexBytes, &i.StoredColumnBytes)
+	return i, err
+}
+
 const getLog = `-- name: GetLog :one
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
 WHERE id = $1
 `
 
-func (q *Queries) GetLog(ctx context.Context, id pgtype.UUID) (Log, error) {
+type GetLogRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) GetLog(ctx context.Context, id pgtype.UUID) (GetLogRow, error) {
 	row := q.db.QueryRow(ctx, getLog, id)
-	var i Log
+	var i GetLogRow
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
@@ -155,6 +397,46 @@ func (q *Queries) GetLog(ctx context.Context, id pgtype.UUID) (Log, error) {
 	return i, err
 }
 
+const listLogContentAfter = `-- name: ListLogContentAfter :many
+SELECT id, content
+FROM logs
+WHERE $1::uuid IS NULL OR id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListLogContentAfterParams struct {
+	AfterID   pgtype.UUID `json:"after_id"`
+	BatchSize int32       `json:"batch_size"`
+}
+
+type ListLogContentAfterRow struct {
+	ID      pgtype.UUID `json:"id"`
+	Content []byte      `json:"content"`
+}
+
+// One batch of rows in id order, starting after after_id (or at the first row
+// when it is NULL), for jobs that walk the whole table.
+func (q *Queries) ListLogContentAfter(ctx context.Context, arg ListLogContentAfterParams) ([]ListLogContentAfterRow, error) {
+	rows, err := q.db.Query(ctx, listLogContentAfter, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLogContentAfterRow{}
+	for rows.Next() {
+		var i ListLogContentAfterRow
+		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listLogs = `-- name: ListLogs :many
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
@@ -167,15 +449,24 @@ type ListLogsParams struct {
 	Offset int32 `json:"offset"`
 }
 
-func (q *Queries) ListLogs(ctx context.Context, arg ListLogsParams) ([]Log, error) {
+type ListLogsRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListLogs(ctx context.Context, arg ListLogsParams) ([]ListLogsRow, error) {
 	rows, err := q.db.Query(ctx, listLogs, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Log{}
+	items := []ListLogsRow{}
 	for rows.Next() {
-		var i Log
+		var i ListLogsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -208,15 +499,24 @@ type ListLogsByDomainParams struct {
 	Offset int32  `json:"offset"`
 }
 
-func (q *Queries) ListLogsByDomain(ctx context.Context, arg ListLogsByDomainParams) ([]Log, error) {
+type ListLogsByDomainRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListLogsByDomain(ctx context.Context, arg ListLogsByDomainParams) ([]ListLogsByDomainRow, error) {
 	rows, err := q.db.Query(ctx, listLogsByDomain, arg.Domain, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Log{}
+	items := []ListLogsByDomainRow{}
 	for rows.Next() {
-		var i Log
+		var i ListLogsByDomainRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -249,15 +549,24 @@ type ListLogsByUserIDParams struct {
 	Offset int32       `json:"offset"`
 }
 
-func (q *Queries) ListLogsByUserID(ctx context.Context, arg ListLogsByUserIDParams) ([]Log, error) {
+type ListLogsByUserIDRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListLogsByUserID(ctx context.Context, arg ListLogsByUserIDParams) ([]ListLogsByUserIDRow, error) {
 	rows, err := q.db.Query(ctx, listLogsByUserID, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Log{}
+	items := []ListLogsByUserIDRow{}
 	for rows.Next() {
-		var i Log
+		var i ListLogsByUserIDRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -279,6 +588,92 @@ func (q *Queries) ListLogsByUserID(ctx context.Context, arg ListLogsByUserIDPara
 const listLogsWithFilters = `-- name: ListLogsWithFilters :many
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
+WHERE
+    log_matches(logs,
+        $3::uuid, $4::text, $5::timestamptz, $6::timestamptz,
+        $7::text, $8::text, $9::text, $10::text,
+        NULL::text, $11::text,
+        $12::jsonb, $13::text, $14::text)
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListLogsWithFiltersParams struct {
+	Limit         int32              `json:"limit"`
+	Offset        int32              `json:"offset"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        pgtype.Text        `json:"domain"`
+	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
+	ContentSearch pgtype.Text        `json:"content_search"`
+	ContentQuery  pgtype.Text        `json:"content_query"`
+	SimpleQuery   pgtype.Text        `json:"simple_query"`
+	CjkQuery      pgtype.Text        `json:"cjk_query"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
+}
+
+type ListLogsWithFiltersRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Filters go through log_matches (migration 00006), shared with the other
+// list, count and export queries; NULL arguments disable their filter.
+// field_path (a jsonpath from search.ParseField) limits content matches to the
+// values it selects. It is checked on the rows the content_tsv, simple or
+// content_cjk_tsv predicates already matched through their indexes.
+func (q *Queries) ListLogsWithFilters(ctx context.Context, arg ListLogsWithFiltersParams) ([]ListLogsWithFiltersRow, error) {
+	rows, err := q.db.Query(ctx, listLogsWithFilters,
+		arg.Limit,
+		arg.Offset,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.ContentSearch,
+		arg.ContentQuery,
+		arg.SimpleQuery,
+		arg.CjkQuery,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLogsWithFiltersRow{}
+	for rows.Next() {
+		var i ListLogsWithFiltersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Domain,
+			&i.Action,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLogsWithFiltersExpression = `-- name: ListLogsWithFiltersExpression :many
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
 WHERE 
     ($3::uuid IS NULL OR user_id = $3) AND
     ($4::text IS NULL OR domain = $4) AND
@@ -289,7 +684,7 @@ ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
 
-type ListLogsWithFiltersParams struct {
+type ListLogsWithFiltersExpressionParams struct {
 	Limit         int32              `json:"limit"`
 	Offset        int32              `json:"offset"`
 	UserID        pgtype.UUID        `json:"user_id"`
@@ -299,8 +694,19 @@ type ListLogsWithFiltersParams struct {
 	ContentSearch pgtype.Text        `json:"content_search"`
 }
 
-func (q *Queries) ListLogsWithFilters(ctx context.Context, arg ListLogsWithFiltersParams) ([]Log, error) {
-	rows, err := q.db.Query(ctx, listLogsWithFilters,
+type ListLogsWithFiltersExpressionRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Same as ListLogsWithFilters but matches against the idx_logs_content_fts
+// expression index instead of the stored content_tsv column.
+func (q *Queries) ListLogsWithFiltersExpression(ctx context.Context, arg ListLogsWithFiltersExpressionParams) ([]ListLogsWithFiltersExpressionRow, error) {
+	rows, err := q.db.Query(ctx, listLogsWithFiltersExpression,
 		arg.Limit,
 		arg.Offset,
 		arg.UserID,
@@ -313,9 +719,96 @@ func (q *Queries) ListLogsWithFilters(ctx context.Context, arg ListLogsWithFilte
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Log{}
+	items := []ListLogsWithFiltersExpressionRow{}
+	for rows.Next() {
+		var i ListLogsWithFiltersExpressionRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Domain,
+			&i.Action,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLogsWithFiltersKeyset = `-- name: ListLogsWithFiltersKeyset :many
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        $2::uuid, $3::text, $4::timestamptz, $5::timestamptz,
+        $6::text, $7::text, $8::text, $9::text,
+        NULL::text, $10::text,
+        $11::jsonb, $12::text, $13::text) AND
+    ($14::timestamptz IS NULL OR (created_at, id) < ($14, $15::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+type ListLogsWithFiltersKeysetParams struct {
+	Limit          int32              `json:"limit"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	Domain         pgtype.Text        `json:"domain"`
+	CreatedAtFrom  pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo    pgtype.Timestamptz `json:"created_at_to"`
+	ContentSearch  pgtype.Text        `json:"content_search"`
+	ContentQuery   pgtype.Text        `json:"content_query"`
+	SimpleQuery    pgtype.Text        `json:"simple_query"`
+	CjkQuery       pgtype.Text        `json:"cjk_query"`
+	FieldPath      pgtype.Text        `json:"field_path"`
+	JsonContains   []byte             `json:"json_contains"`
+	JsonMatch      pgtype.Text        `json:"json_match"`
+	JsonPath       pgtype.Text        `json:"json_path"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+}
+
+type ListLogsWithFiltersKeysetRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Keyset pagination over (created_at, id): returns the rows that come after
+// the (after_created_at, after_id) cursor in created_at DESC, id DESC order,
+// or the first page when the cursor is NULL.
+func (q *Queries) ListLogsWithFiltersKeyset(ctx context.Context, arg ListLogsWithFiltersKeysetParams) ([]ListLogsWithFiltersKeysetRow, error) {
+	rows, err := q.db.Query(ctx, listLogsWithFiltersKeyset,
+		arg.Limit,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.ContentSearch,
+		arg.ContentQuery,
+		arg.SimpleQuery,
+		arg.CjkQuery,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLogsWithFiltersKeysetRow{}
 	for rows.Next() {
-		var i Log
+		var i ListLogsWithFiltersKeysetRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -337,14 +830,14 @@ func (q *Queries) ListLogsWithFilters(ctx context.Context, arg ListLogsWithFilte
 const searchLogsPartial = `-- name: SearchLogsPartial :many
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
-WHERE 
-    ($1::uuid IS NULL OR user_id = $1) AND
-    ($2::text IS NULL OR domain = $2) AND
-    ($3::timestamptz IS NULL OR created_at >= $3) AND
-    ($4::timestamptz IS NULL OR created_at <= $4) AND
-    content::text ILIKE '%' || $5::text || '%'
+WHERE
+    log_matches(logs,
+        $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        $5::text, $6::text,
+        $7::jsonb, $8::text, $9::text)
 ORDER BY created_at DESC
-LIMIT $7 OFFSET $6
+LIMIT $11 OFFSET $10
 `
 
 type SearchLogsPartialParams struct {
@@ -353,17 +846,36 @@ type SearchLogsPartialParams struct {
 	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
 	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
 	SearchTerm    pgtype.Text        `json:"search_term"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
 	Offset        pgtype.Int4        `json:"offset"`
 	Limit         pgtype.Int4        `json:"limit"`
 }
 
-func (q *Queries) SearchLogsPartial(ctx context.Context, arg SearchLogsPartialParams) ([]Log, error) {
+type SearchLogsPartialRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// field_path limits matches to the values it selects, as in
+// ListLogsWithFilters; the trigram index still finds the candidate rows.
+func (q *Queries) SearchLogsPartial(ctx context.Context, arg SearchLogsPartialParams) ([]SearchLogsPartialRow, error) {
 	rows, err := q.db.Query(ctx, searchLogsPartial,
 		arg.UserID,
 		arg.Domain,
 		arg.CreatedAtFrom,
 		arg.CreatedAtTo,
 		arg.SearchTerm,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -371,9 +883,183 @@ func (q *Queries) SearchLogsPartial(ctx context.Context, arg SearchLogsPartialPa
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Log{}
+	items := []SearchLogsPartialRow{}
+	for rows.Next() {
+		var i SearchLogsPartialRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Domain,
+			&i.Action,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchLogsPartialKeyset = `-- name: SearchLogsPartialKeyset :many
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        $1::uuid, $2::text, $3::timestamptz, $4::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        $5::text, $6::text,
+        $7::jsonb, $8::text, $9::text) AND
+    ($10::timestamptz IS NULL OR (created_at, id) < ($10, $11::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $12
+`
+
+type SearchLogsPartialKeysetParams struct {
+	UserID         pgtype.UUID        `json:"user_id"`
+	Domain         pgtype.Text        `json:"domain"`
+	CreatedAtFrom  pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo    pgtype.Timestamptz `json:"created_at_to"`
+	SearchTerm     pgtype.Text        `json:"search_term"`
+	FieldPath      pgtype.Text        `json:"field_path"`
+	JsonContains   []byte             `json:"json_contains"`
+	JsonMatch      pgtype.Text        `json:"json_match"`
+	JsonPath       pgtype.Text        `json:"json_path"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Limit          pgtype.Int4        `json:"limit"`
+}
+
+type SearchLogsPartialKeysetRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Keyset variant of SearchLogsPartial; see ListLogsWithFiltersKeyset.
+func (q *Queries) SearchLogsPartialKeyset(ctx context.Context, arg SearchLogsPartialKeysetParams) ([]SearchLogsPartialKeysetRow, error) {
+	rows, err := q.db.Query(ctx, searchLogsPartialKeyset,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.SearchTerm,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchLogsPartialKeysetRow{}
+	for rows.Next() {
+		var i SearchLogsPartialKeysetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Domain,
+			&i.Action,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchLogsRanked = `-- name: SearchLogsRanked :many
+SELECT id, user_id, domain, action, content, created_at, rank,
+    ts_headline('english', content::text, COALESCE(to_tsquery('english', $3), plainto_tsquery('english', $4)),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" … "')::text AS headline
+FROM (
+    SELECT id, user_id, domain, action, content, created_at,
+        (CASE WHEN $5::boolean
+            THEN ts_rank_cd(content_tsv, COALESCE(to_tsquery('english', $3), plainto_tsquery('english', $4)))
+            ELSE ts_rank(content_tsv, COALESCE(to_tsquery('english', $3), plainto_tsquery('english', $4)))
+        END)::real AS rank
+    FROM logs
+    WHERE
+        log_matches(logs,
+            $6::uuid, $7::text, $8::timestamptz, $9::timestamptz,
+            $4::text, $3::text, NULL::text, NULL::text,
+            NULL::text, $10::text,
+            $11::jsonb, $12::text, $13::text)
+    ORDER BY rank DESC, created_at DESC, id DESC
+    LIMIT $1 OFFSET $2
+) ranked
+ORDER BY rank DESC, created_at DESC, id DESC
+`
+
+type SearchLogsRankedParams struct {
+	Limit         int32              `json:"limit"`
+	Offset        int32              `json:"offset"`
+	ContentQuery  pgtype.Text        `json:"content_query"`
+	ContentSearch pgtype.Text        `json:"content_search"`
+	RankCd        bool               `json:"rank_cd"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	Domain        pgtype.Text        `json:"domain"`
+	CreatedAtFrom pgtype.Timestamptz `json:"created_at_from"`
+	CreatedAtTo   pgtype.Timestamptz `json:"created_at_to"`
+	FieldPath     pgtype.Text        `json:"field_path"`
+	JsonContains  []byte             `json:"json_contains"`
+	JsonMatch     pgtype.Text        `json:"json_match"`
+	JsonPath      pgtype.Text        `json:"json_path"`
+}
+
+type SearchLogsRankedRow struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Domain    string             `json:"domain"`
+	Action    string             `json:"action"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Rank      float32            `json:"rank"`
+	Headline  string             `json:"headline"`
+}
+
+// Full-text matches ordered by relevance, with ts_rank_cd (cover density)
+// instead of ts_rank when rank_cd is set. The query is content_query (tsquery
+// syntax) when given, else content_search (plain text). ts_headline re-parses
+// the document, so it only runs on the rows of the requested page.
+func (q *Queries) SearchLogsRanked(ctx context.Context, arg SearchLogsRankedParams) ([]SearchLogsRankedRow, error) {
+	rows, err := q.db.Query(ctx, searchLogsRanked,
+		arg.Limit,
+		arg.Offset,
+		arg.ContentQuery,
+		arg.ContentSearch,
+		arg.RankCd,
+		arg.UserID,
+		arg.Domain,
+		arg.CreatedAtFrom,
+		arg.CreatedAtTo,
+		arg.FieldPath,
+		arg.JsonContains,
+		arg.JsonMatch,
+		arg.JsonPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchLogsRankedRow{}
 	for rows.Next() {
-		var i Log
+		var i SearchLogsRankedRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -381,6 +1067,8 @@ func (q *Queries) SearchLogsPartial(ctx context.Context, arg SearchLogsPartialPa
 			&i.Action,
 			&i.Content,
 			&i.CreatedAt,
+			&i.Rank,
+			&i.Headline,
 		); err != nil {
 			return nil, err
 		}
@@ -400,3 +1088,37 @@ func (q *Queries) TruncateLogs(ctx context.Context) error {
 	_, err := q.db.Exec(ctx, truncateLogs)
 	return err
 }
+
+const updateContentCJK = `-- name: UpdateContentCJK :execrows
+UPDATE logs
+SET content_cjk = NULLIF(batch.content_cjk, '')
+FROM (
+    SELECT unnest($1::uuid[]) AS id, unnest($2::text[]) AS content_cjk
+) AS batch
+WHERE logs.id = batch.id AND logs.content_cjk IS DISTINCT FROM NULLIF(batch.content_cjk, '')
+`
+
+type UpdateContentCJKParams struct {
+	Ids        []pgtype.UUID `json:"ids"`
+	ContentCjk []string      `json:"content_cjk"`
+}
+
+// Sets content_cjk for a batch of rows; an empty string stores NULL. Rows
+// that already hold the value are not rewritten.
+func (q *Queries) UpdateContentCJK(ctx context.Context, arg UpdateContentCJKParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateContentCJK, arg.Ids, arg.ContentCjk)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const validateJSONPath = `-- name: ValidateJSONPath :exec
+SELECT $1::text::jsonpath
+`
+
+// Fails with a syntax error when path is not a valid jsonpath.
+func (q *Queries) ValidateJSONPath(ctx context.Context, path string) error {
+	_, err := q.db.Exec(ctx, validateJSONPath, path)
+	return err
+}
@@ -49,7 +49,7 @@ func main() {
 	log.Println("Database initialized successfully with pgx pool")
 
 	// Initialize handlers
-	h := handlers.New(pool)
+	h := handlers.New(pool, cfg)
 
 	// Setup Gin router
 	r := gin.Default()
@@ -76,7 +76,12 @@ func main() {
 	api := r.Group("/api")
 	{
 		api.POST("/initialize", h.InitializeData)
+		api.GET("/jobs", h.ListJobs)
+		api.GET("/jobs/:id", h.GetJob)
+		api.DELETE("/jobs/:id", h.CancelJob)
 		api.GET("/logs", h.GetLogs)
+		api.POST("/logs/bulk", h.BulkIngestLogs)
+		api.GET("/logs/export", h.ExportLogs)
 		api.GET("/search/partial", h.SearchLogsPartial)
 		api.DELETE("/truncate", h.TruncateDatabase)
 
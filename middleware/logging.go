@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"log-project/internal/db"
+	"log-project/search"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -84,11 +85,12 @@ func RequestLogger(pool *pgxpool.Pool) gin.HandlerFunc {
 			pgCreatedAt.Valid = true
 
 			_, err = queries.CreateLog(c.Request.Context(), db.CreateLogParams{
-				UserID:    pgUserID,
-				Domain:    "example-api",
-				Action:    c.Request.Method + " " + c.Request.URL.Path,
-				Content:   contentBytes,
-				CreatedAt: pgCreatedAt,
+				UserID:     pgUserID,
+				Domain:     "example-api",
+				Action:     c.Request.Method + " " + c.Request.URL.Path,
+				Content:    contentBytes,
+				ContentCjk: search.ContentCJK(contentBytes),
+				CreatedAt:  pgCreatedAt,
 			})
 			if err != nil {
 				log.Printf("Failed to create log: %v", err)
@@ -44,17 +44,63 @@ func (c Content) Value() (driver.Value, error) {
 }
 
 type InitializeRequest struct {
-	RecordCount int    `json:"record_count" binding:"required,oneof=1000 10000 100000 1000000 10000000"`
-	ContentSize string `json:"content_size" binding:"required,oneof=small medium large"`
+	// Exactly one of RecordCount and TargetTotalBytes sets the dataset size.
+	// RecordCount is capped by the server's MAX_RECORD_COUNT. TargetTotalBytes
+	// is an on-disk size (pg_total_relation_size: heap, TOAST and indexes),
+	// converted to a record count by sampling and capped the same way.
+	RecordCount      int   `json:"record_count" binding:"required_without=TargetTotalBytes,omitempty,min=1"`
+	TargetTotalBytes int64 `json:"target_total_bytes" binding:"omitempty,min=1"`
+
+	// TargetRowBytes pads each document's JSON to about this many bytes.
+	TargetRowBytes int `json:"target_row_bytes" binding:"omitempty,min=1,max=104857600"`
+
+	ContentSize string `json:"content_size" binding:"required_without=Profile,omitempty,oneof=small medium large"`
+	Seed        int64  `json:"seed"`
+	Generators  int    `json:"generators" binding:"omitempty,min=1,max=64"`
+	Writers     int    `json:"writers" binding:"omitempty,min=1,max=64"`
+
+	// Profile is an inline content profile (see utils.Profile) used instead
+	// of ContentSize.
+	Profile json.RawMessage `json:"profile" swaggertype:"object"`
+
+	Users              int               `json:"users" binding:"omitempty,min=1"`
+	UserDistribution   *DistributionSpec `json:"user_distribution"`
+	DomainDistribution *DistributionSpec `json:"domain_distribution"`
+	ActionDistribution *DistributionSpec `json:"action_distribution"`
+	TimePattern        string            `json:"time_pattern" binding:"omitempty,oneof=uniform diurnal bursts"`
+	TimeWindowDays     int               `json:"time_window_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// DistributionSpec selects how generated rows are spread over a set of keys.
+// Skew applies to zipf; HotKeys and HotShare to hotkey.
+type DistributionSpec struct {
+	Type     string  `json:"type" binding:"required,oneof=uniform zipf hotkey"`
+	Skew     float64 `json:"skew" binding:"omitempty,gt=1"`
+	HotKeys  float64 `json:"hot_keys" binding:"omitempty,gt=0,lt=1"`
+	HotShare float64 `json:"hot_share" binding:"omitempty,gt=0,lt=1"`
 }
 
 type LogFilter struct {
-	UserID      *string `form:"user_id"`
-	Domain      *string `form:"domain"`
-	CreatedAt   *string `form:"created_at"`
-	CreatedAtTo *string `form:"created_at_to"`
-	ContentLike *string `form:"content_like"`
-	SearchTerm  *string `form:"search_term"`
-	Page        int     `form:"page,default=1"`
-	Limit       int     `form:"limit,default=50"`
+	UserID      *string  `form:"user_id"`
+	Domain      *string  `form:"domain"`
+	CreatedAt   *string  `form:"created_at"`
+	CreatedAtTo *string  `form:"created_at_to"`
+	ContentLike *string  `form:"content_like"`
+	SearchTerm  *string  `form:"search_term"`
+	Field       *string  `form:"field"`
+	JSONEq      []string `form:"json_eq"`
+	JSONHas     []string `form:"json_has"`
+	JSONRange   []string `form:"json_range"`
+	JSONPath    *string  `form:"jsonpath"`
+	Cursor      *string  `form:"cursor"`
+	Count       string   `form:"count" binding:"omitempty,oneof=exact estimated capped none"`
+	Syntax      string   `form:"syntax" binding:"omitempty,oneof=plain websearch"`
+	Sort        string   `form:"sort" binding:"omitempty,oneof=created_at relevance"`
+	RankFn      string   `form:"rank_fn" binding:"omitempty,oneof=ts_rank ts_rank_cd"`
+	TSConfig    string   `form:"ts_config" binding:"omitempty,oneof=english simple cjk"`
+
+	// The caps keep Limit+1 and the OFFSET (Page-1)*Limit within the int32
+	// the queries take.
+	Page  int `form:"page,default=1" binding:"min=1,max=1000000"`
+	Limit int `form:"limit,default=50" binding:"min=1,max=1000"`
 }
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// isCJK reports whether r belongs to a script written without spaces between
+// words: Han (kanji), Hiragana, Katakana (including the ー prolonged sound
+// mark) and Hangul.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// ContentCJK returns the content_cjk column for a JSON document: every run of
+// CJK characters in its strings, split into overlapping bigrams ("ログイン" →
+// "ログ グイ イン ン"). The last character of each run is repeated on its own,
+// so single-character queries can match it as a prefix. The column is NULL
+// when the document has no CJK text.
+func ContentCJK(content []byte) pgtype.Text {
+	// Most documents are ASCII; only those with multi-byte UTF-8 or \u
+	// escapes can hold CJK characters.
+	if !bytes.Contains(content, []byte(`\u`)) && !hasNonASCII(content) {
+		return pgtype.Text{}
+	}
+
+	var b strings.Builder
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		if s, ok := tok.(string); ok {
+			appendBigrams(&b, s)
+		}
+	}
+	if b.Len() == 0 {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: b.String(), Valid: true}
+}
+
+func hasNonASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
+// appendBigrams writes the bigrams of every CJK run in s to b, space separated.
+func appendBigrams(b *strings.Builder, s string) {
+	var run []rune
+	flush := func() {
+		for i := range run {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			if i+1 < len(run) {
+				b.WriteString(string(run[i : i+2]))
+			} else {
+				b.WriteRune(run[i])
+			}
+		}
+		run = run[:0]
+	}
+	for _, r := range s {
+		if isCJK(r) {
+			run = append(run, r)
+		} else if len(run) > 0 {
+			flush()
+		}
+	}
+	flush()
+}
+
+// cjkTerm renders a query term against content_cjk: the phrase of its bigrams,
+// or a prefix match for a single character. A trailing * is dropped since
+// bigrams already match anywhere inside a run.
+func cjkTerm(w string) (string, error) {
+	w = strings.TrimSuffix(w, "*")
+
+	var runs [][]rune
+	var run []rune
+	for _, r := range w {
+		switch {
+		case isCJK(r):
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return "", fmt.Errorf("%q: ts_config=cjk only matches Chinese, Japanese and Korean text; use ts_config=english or simple for other words", w)
+		default:
+			// Punctuation splits runs, as it does in ContentCJK
+			if len(run) > 0 {
+				runs = append(runs, run)
+				run = nil
+			}
+		}
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 {
+		return "", fmt.Errorf("%q has no Chinese, Japanese or Korean characters to search for", w)
+	}
+
+	parts := make([]string, len(runs))
+	for i, run := range runs {
+		if len(run) == 1 {
+			parts[i] = quoteTerm(string(run) + "*")
+			continue
+		}
+		grams := make([]string, len(run)-1)
+		for j := range grams {
+			grams[j] = quoteTerm(string(run[j : j+2]))
+		}
+		parts[i] = strings.Join(grams, " <-> ")
+	}
+	if len(parts) == 1 && len(runs[0]) <= 2 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " & ") + ")", nil
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentCJK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		null    bool
+	}{
+		{name: "ascii only", content: `{"message":"login failed"}`, null: true},
+		{name: "non-cjk multi-byte", content: `{"message":"café naïve"}`, null: true},
+		{name: "single char", content: `{"message":"失"}`, want: "失"},
+		{name: "run", content: `{"message":"ログイン"}`, want: "ログ グイ イン ン"},
+		{name: "runs split by latin", content: `{"message":"ログin失敗"}`, want: "ログ グ 失敗 敗"},
+		{name: "keys are indexed", content: `{"状態":"ok"}`, want: "状態 態"},
+		{name: "nested and arrays", content: `{"a":{"b":["成功","失敗"]}}`, want: "成功 功 失敗 敗"},
+		{name: "unicode escapes", content: `{"message":"\u30ed\u30b0"}`, want: "ログ グ"},
+		{name: "korean", content: `{"message":"한국어"}`, want: "한국 국어 어"},
+		{name: "malformed json keeps what it read", content: `{"message":"ログ", "x":`, want: "ログ グ"},
+		{name: "not json", content: `ログ`, null: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ContentCJK([]byte(tt.content))
+			if tt.null {
+				if got.Valid {
+					t.Fatalf("got %q, want NULL", got.String)
+				}
+				return
+			}
+			if !got.Valid || got.String != tt.want {
+				t.Fatalf("got %q (valid %v), want %q", got.String, got.Valid, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "words", input: "payment failed", want: "'payment' & 'failed'"},
+		{name: "syntax is literal", input: `"a" -b OR c*`, want: `'"a"' & '-b' & 'OR' & 'c*'`},
+		{name: "quote escaped", input: "it's", want: "'it''s'"},
+		{name: "japanese", input: "ログイン", want: "'ログイン'"},
+		{name: "empty", input: " ", wantErr: "query is empty"},
+		{name: "too many terms", input: strings.Repeat("a ", MaxQueryTerms+1), wantErr: "more than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlainQuery(tt.input)
+			checkQuery(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestQueryCJK(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (string, error)
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "plain single char", parse: PlainQueryCJK, input: "ロ", want: "'ロ':*"},
+		{name: "plain bigram", parse: PlainQueryCJK, input: "ログ", want: "'ログ'"},
+		{name: "plain run", parse: PlainQueryCJK, input: "ログイン", want: "('ログ' <-> 'グイ' <-> 'イン')"},
+		{name: "plain two words", parse: PlainQueryCJK, input: "ログ 失敗", want: "'ログ' & '失敗'"},
+		{name: "plain trailing star dropped", parse: PlainQueryCJK, input: "ログ*", want: "'ログ'"},
+		{name: "punctuation splits runs", parse: PlainQueryCJK, input: "ログ、失敗", want: "('ログ' & '失敗')"},
+		{name: "korean", parse: PlainQueryCJK, input: "한국어", want: "('한국' <-> '국어')"},
+		{name: "prolonged sound mark", parse: PlainQueryCJK, input: "データ", want: "('デー' <-> 'ータ')"},
+		{name: "web phrase", parse: ParseWebSearchCJK, input: `"ログ 失敗"`, want: "('ログ' <-> '失敗')"},
+		{name: "web or and exclude", parse: ParseWebSearchCJK, input: "ログ OR 失敗 -成功", want: "('ログ' | '失敗') & !'成功'"},
+
+		{name: "latin rejected", parse: PlainQueryCJK, input: "login", wantErr: "ts_config=cjk only matches"},
+		{name: "mixed rejected", parse: PlainQueryCJK, input: "ログin", wantErr: "ts_config=cjk only matches"},
+		{name: "digits rejected", parse: ParseWebSearchCJK, input: "失敗 404", wantErr: "ts_config=cjk only matches"},
+		{name: "punctuation only", parse: PlainQueryCJK, input: "、。", wantErr: "no Chinese, Japanese or Korean characters"},
+		{name: "web empty", parse: ParseWebSearchCJK, input: "", wantErr: "query is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.input)
+			checkQuery(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
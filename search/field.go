@@ -0,0 +1,80 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxFieldDepth caps how many keys a field path may have.
+const MaxFieldDepth = 16
+
+// ParseField converts a field path into a jsonpath selecting every scalar
+// value at or below it:
+//
+//	content.status                  the status key (the content. prefix is optional)
+//	user.address.city               nested keys
+//	nested_obj_*.nested_field_0     * matches any run of characters in a key
+//	*.id                            id one level down, under any key
+//
+// Arrays along the path are searched element by element. Keys may only hold
+// letters, digits, _, - and *, which keeps the generated jsonpath (including
+// the like_regex for wildcards) free of anything that needs escaping.
+func ParseField(field string) (string, error) {
+	keys, err := parseKeys(field)
+	if err != nil {
+		return "", err
+	}
+	// Objects and arrays under the field are searched by their values, not
+	// their keys
+	return "lax " + keyPath(keys) + `.** ? (@.type() != "object" && @.type() != "array")`, nil
+}
+
+// parseKeys splits a field path into its keys, dropping the optional content.
+// prefix.
+func parseKeys(field string) ([]string, error) {
+	field = strings.TrimSpace(field)
+	if field == "content" {
+		return nil, errors.New("field must name a key inside content")
+	}
+	field = strings.TrimPrefix(field, "content.")
+	if field == "" {
+		return nil, errors.New("field is empty")
+	}
+
+	keys := strings.Split(field, ".")
+	if len(keys) > MaxFieldDepth {
+		return nil, fmt.Errorf("field has more than %d keys", MaxFieldDepth)
+	}
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("%q has an empty key", field)
+		}
+		for _, r := range key {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '*' {
+				return nil, fmt.Errorf("%q: keys may only contain letters, digits, _, - and *", field)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// keyPath renders keys from parseKeys as a jsonpath accessor chain.
+func keyPath(keys []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range keys {
+		switch {
+		case key == "*":
+			b.WriteString(".*")
+		case strings.Contains(key, "*"):
+			// Outside of * every allowed character matches itself in a regex
+			pattern := "^" + strings.ReplaceAll(key, "*", ".*") + "$"
+			fmt.Fprintf(&b, `.keyvalue() ? (@.key like_regex "%s").value`, pattern)
+		default:
+			fmt.Fprintf(&b, `."%s"`, key)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseField(t *testing.T) {
+	const scalars = `.** ? (@.type() != "object" && @.type() != "array")`
+	tests := []struct {
+		name    string
+		field   string
+		want    string
+		wantErr string
+	}{
+		{name: "key", field: "status", want: `lax $."status"` + scalars},
+		{name: "content prefix", field: "content.status", want: `lax $."status"` + scalars},
+		{name: "nested", field: "user.address.city", want: `lax $."user"."address"."city"` + scalars},
+		{name: "surrounding space", field: " status ", want: `lax $."status"` + scalars},
+		{name: "wildcard key", field: "nested_obj_*.nested_field_0", want: `lax $.keyvalue() ? (@.key like_regex "^nested_obj_.*$").value."nested_field_0"` + scalars},
+		{name: "bare wildcard", field: "*.id", want: `lax $.*."id"` + scalars},
+		{name: "dash and digits", field: "x-request-id2", want: `lax $."x-request-id2"` + scalars},
+		{name: "japanese key", field: "ユーザー.名前", want: `lax $."ユーザー"."名前"` + scalars},
+
+		{name: "content alone", field: "content", wantErr: "must name a key inside content"},
+		{name: "empty", field: "", wantErr: "field is empty"},
+		{name: "empty after prefix", field: "content.", wantErr: "field is empty"},
+		{name: "empty key", field: "user..city", wantErr: "has an empty key"},
+		{name: "trailing dot", field: "user.", wantErr: "has an empty key"},
+		{name: "quote", field: `user"`, wantErr: "keys may only contain"},
+		{name: "jsonpath syntax", field: "a[0]", wantErr: "keys may only contain"},
+		{name: "space in key", field: "a b", wantErr: "keys may only contain"},
+		{name: "cjk punctuation", field: "名前、", wantErr: "keys may only contain"},
+		{name: "too deep", field: strings.Repeat("a.", MaxFieldDepth) + "a", wantErr: "more than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseField(tt.field)
+			checkQuery(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
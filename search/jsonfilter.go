@@ -0,0 +1,147 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxJSONConditions caps how many json_eq, json_has and json_range
+// conditions a filter may have in total.
+const MaxJSONConditions = 32
+
+// JSONFilter is a set of structured conditions on content: equalities become
+// one containment document (content @> Contains), key existence and numeric
+// ranges one jsonpath predicate (content @@ Match). idx_logs_content_gin
+// answers the containment and the exists() terms of Match. Ranges and
+// wildcard keys are not indexable; they are checked on each row the indexed
+// conditions leave, or on every row when there are none.
+type JSONFilter struct {
+	Contains []byte // nil without equalities
+	Match    string // "" without key or range conditions
+}
+
+// jsonNumber is a jsonpath numeric literal.
+var jsonNumber = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+
+// rangeOps are the comparisons json_range accepts, two-character ones first
+// so ">=" is not read as ">".
+var rangeOps = []string{">=", "<=", ">", "<"}
+
+// ParseJSONFilter compiles the json_eq, json_has and json_range parameters:
+//
+//	json_eq=status:error          status is the string "error"
+//	json_eq=retry_count:3         a value that parses as JSON (number, true, "quoted") is used as is
+//	json_has=nested_obj_1         the key exists, whatever its value
+//	json_range=duration>1000      a numeric comparison: >, >=, < or <=
+//
+// Paths are field paths as in ParseField. json_has and json_range accept *
+// in keys. json_eq does not, since a containment document can only hold
+// literal keys. Match is a strict mode jsonpath: the GIN index only answers
+// exists() in strict mode, and strict paths do not step into arrays.
+func ParseJSONFilter(eq, has, ranges []string) (JSONFilter, error) {
+	var f JSONFilter
+	if len(eq)+len(has)+len(ranges) > MaxJSONConditions {
+		return f, fmt.Errorf("more than %d JSON conditions", MaxJSONConditions)
+	}
+
+	if len(eq) > 0 {
+		doc := map[string]interface{}{}
+		for _, cond := range eq {
+			field, value, ok := strings.Cut(cond, ":")
+			if !ok {
+				return f, fmt.Errorf("json_eq %q: want path:value", cond)
+			}
+			keys, err := parseKeys(field)
+			if err != nil {
+				return f, fmt.Errorf("json_eq %q: %w", cond, err)
+			}
+			if strings.Contains(field, "*") {
+				return f, fmt.Errorf("json_eq %q: * is only supported by json_has and json_range", cond)
+			}
+			if err := setPath(doc, keys, jsonValue(value)); err != nil {
+				return f, fmt.Errorf("json_eq %q: %w", cond, err)
+			}
+		}
+		contains, err := json.Marshal(doc)
+		if err != nil {
+			return f, err
+		}
+		f.Contains = contains
+	}
+
+	var preds []string
+	for _, field := range has {
+		keys, err := parseKeys(field)
+		if err != nil {
+			return f, fmt.Errorf("json_has %q: %w", field, err)
+		}
+		preds = append(preds, "exists("+keyPath(keys)+")")
+	}
+	for _, cond := range ranges {
+		pred, err := rangePredicate(cond)
+		if err != nil {
+			return f, fmt.Errorf("json_range %q: %w", cond, err)
+		}
+		preds = append(preds, pred)
+	}
+	if len(preds) > 0 {
+		f.Match = "strict " + strings.Join(preds, " && ")
+	}
+
+	return f, nil
+}
+
+// jsonValue reads a json_eq value: JSON when it parses as JSON, otherwise
+// the plain string.
+func jsonValue(s string) json.RawMessage {
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	quoted, _ := json.Marshal(s)
+	return quoted
+}
+
+// setPath sets keys to value in doc, creating the objects along the way.
+func setPath(doc map[string]interface{}, keys []string, value json.RawMessage) error {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := doc[key]
+		if !ok {
+			child := map[string]interface{}{}
+			doc[key] = child
+			doc = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("conflicts with another json_eq on %q", key)
+		}
+		doc = child
+	}
+	last := keys[len(keys)-1]
+	if _, ok := doc[last]; ok {
+		return fmt.Errorf("conflicts with another json_eq on %q", last)
+	}
+	doc[last] = value
+	return nil
+}
+
+func rangePredicate(cond string) (string, error) {
+	for _, op := range rangeOps {
+		field, value, ok := strings.Cut(cond, op)
+		if !ok {
+			continue
+		}
+		keys, err := parseKeys(field)
+		if err != nil {
+			return "", err
+		}
+		value = strings.TrimSpace(value)
+		if !jsonNumber.MatchString(value) {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		return keyPath(keys) + " " + op + " " + value, nil
+	}
+	return "", fmt.Errorf("want path, an operator (>, >=, < or <=) and a number")
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJSONFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		eq           []string
+		has          []string
+		ranges       []string
+		wantContains string
+		wantMatch    string
+		wantErr      string
+	}{
+		{name: "none"},
+		{name: "string value", eq: []string{"status:error"}, wantContains: `{"status":"error"}`},
+		{name: "json values", eq: []string{"retry_count:3", "ok:true", `code:"500"`}, wantContains: `{"code":"500","ok":true,"retry_count":3}`},
+		{name: "nested values merge", eq: []string{"a.b:1", "a.c:x"}, wantContains: `{"a":{"b":1,"c":"x"}}`},
+		{name: "value with colon", eq: []string{"url:http://x"}, wantContains: `{"url":"http://x"}`},
+		{name: "multi-byte value", eq: []string{"message:ログイン失敗"}, wantContains: `{"message":"ログイン失敗"}`},
+		{name: "has", has: []string{"nested_obj_1"}, wantMatch: `strict exists($."nested_obj_1")`},
+		{name: "has wildcard", has: []string{"nested_obj_*"}, wantMatch: `strict exists($.keyvalue() ? (@.key like_regex "^nested_obj_.*$").value)`},
+		{name: "range ops", ranges: []string{"duration>=5000", "duration<9000.5"}, wantMatch: `strict $."duration" >= 5000 && $."duration" < 9000.5`},
+		{name: "range exponent", ranges: []string{"size<=-1e3"}, wantMatch: `strict $."size" <= -1e3`},
+		{
+			name:         "all kinds",
+			eq:           []string{"status:error"},
+			has:          []string{"a.b"},
+			ranges:       []string{"duration>1"},
+			wantContains: `{"status":"error"}`,
+			wantMatch:    `strict exists($."a"."b") && $."duration" > 1`,
+		},
+
+		{name: "eq without colon", eq: []string{"status"}, wantErr: "want path:value"},
+		{name: "eq wildcard", eq: []string{"nested_*:1"}, wantErr: "* is only supported by json_has and json_range"},
+		{name: "eq same key twice", eq: []string{"status:a", "status:b"}, wantErr: "conflicts with another json_eq"},
+		{name: "eq value under value", eq: []string{"a:1", "a.b:2"}, wantErr: "conflicts with another json_eq"},
+		{name: "eq bad key", eq: []string{"a'b:1"}, wantErr: "keys may only contain"},
+		{name: "has empty key", has: []string{"a..b"}, wantErr: "has an empty key"},
+		{name: "range without operator", ranges: []string{"duration=5"}, wantErr: "want path, an operator"},
+		{name: "range not a number", ranges: []string{"duration>abc"}, wantErr: "is not a number"},
+		{name: "range full-width digits", ranges: []string{"duration>５"}, wantErr: "is not a number"},
+		{name: "range empty path", ranges: []string{">5"}, wantErr: "field is empty"},
+		{name: "too many", has: make([]string, MaxJSONConditions+1), wantErr: "more than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONFilter(tt.eq, tt.has, tt.ranges)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got %+v, want error containing %q", got, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %q does not contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got.Contains) != tt.wantContains {
+				t.Errorf("Contains = %s, want %s", got.Contains, tt.wantContains)
+			}
+			if got.Match != tt.wantMatch {
+				t.Errorf("Match = %q, want %q", got.Match, tt.wantMatch)
+			}
+		})
+	}
+}
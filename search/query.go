@@ -0,0 +1,253 @@
+// Package search turns user-facing search syntax into PostgreSQL tsquery
+// text, and splits CJK content into the bigrams content_cjk indexes.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxQueryTerms caps how many terms a query may have.
+const MaxQueryTerms = 64
+
+// ParseWebSearch converts web-search style input into to_tsquery syntax:
+//
+//	payment failed      both words ('payment' & 'failed')
+//	"payment failed"    the phrase ('payment' <-> 'failed')
+//	-refund             excludes a word or phrase (!'refund')
+//	login OR signup     either word ('login' | 'signup'); OR binds tighter than AND
+//	log*                prefix match ('log':*)
+//
+// Every term is quoted, so punctuation in the input can never break the
+// tsquery syntax; the text-search configuration still normalizes each term
+// when the query runs. Malformed input (an unterminated quote, a dangling OR,
+// a query that only excludes) returns an error meant to be shown to the user.
+func ParseWebSearch(input string) (string, error) {
+	return parseWebSearch(input, func(w string) (string, error) { return quoteTerm(w), nil })
+}
+
+// ParseWebSearchCJK is ParseWebSearch for the content_cjk bigram column: each
+// term becomes the phrase of its bigrams (see ContentCJK).
+func ParseWebSearchCJK(input string) (string, error) {
+	return parseWebSearch(input, cjkTerm)
+}
+
+// PlainQuery converts plain input (words that must all match) into to_tsquery
+// syntax, for configurations without an index plainto_tsquery can use.
+func PlainQuery(input string) (string, error) {
+	return plainQuery(input, func(w string) (string, error) { return quoteLexeme(w), nil })
+}
+
+// PlainQueryCJK is PlainQuery for the content_cjk bigram column.
+func PlainQueryCJK(input string) (string, error) {
+	return plainQuery(input, cjkTerm)
+}
+
+func plainQuery(input string, term func(string) (string, error)) (string, error) {
+	words := strings.Fields(input)
+	if len(words) == 0 {
+		return "", errors.New("query is empty")
+	}
+	if len(words) > MaxQueryTerms {
+		return "", fmt.Errorf("query has more than %d terms", MaxQueryTerms)
+	}
+	parts := make([]string, len(words))
+	for i, w := range words {
+		expr, err := term(w)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = expr
+	}
+	return strings.Join(parts, " & "), nil
+}
+
+// parseWebSearch parses web-search syntax, rendering each word with term.
+func parseWebSearch(input string, term func(string) (string, error)) (string, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", errors.New("query is empty")
+	}
+
+	var groups [][]string
+	positive := false
+	terms := 0
+	pendingOr := false
+	for i, tok := range tokens {
+		if tok.or {
+			if i == 0 || pendingOr {
+				return "", errors.New("OR must be between two terms")
+			}
+			pendingOr = true
+			continue
+		}
+
+		terms += len(tok.words)
+		if terms > MaxQueryTerms {
+			return "", fmt.Errorf("query has more than %d terms", MaxQueryTerms)
+		}
+		if !tok.negate {
+			positive = true
+		}
+
+		expr, err := tok.expr(term)
+		if err != nil {
+			return "", err
+		}
+		if pendingOr {
+			groups[len(groups)-1] = append(groups[len(groups)-1], expr)
+			pendingOr = false
+		} else {
+			groups = append(groups, []string{expr})
+		}
+	}
+	if pendingOr {
+		return "", errors.New("OR must be between two terms")
+	}
+	if !positive {
+		return "", errors.New("query needs at least one term that is not excluded")
+	}
+
+	parts := make([]string, len(groups))
+	for i, g := range groups {
+		if len(g) == 1 {
+			parts[i] = g[0]
+		} else {
+			parts[i] = "(" + strings.Join(g, " | ") + ")"
+		}
+	}
+	return strings.Join(parts, " & "), nil
+}
+
+// token is a word, a quoted phrase or the OR operator.
+type token struct {
+	words  []string
+	phrase bool
+	negate bool
+	or     bool
+}
+
+func (t token) expr(term func(string) (string, error)) (string, error) {
+	lexemes := make([]string, len(t.words))
+	for i, w := range t.words {
+		lexeme, err := term(w)
+		if err != nil {
+			return "", err
+		}
+		lexemes[i] = lexeme
+	}
+
+	expr := lexemes[0]
+	if t.phrase && len(lexemes) > 1 {
+		expr = "(" + strings.Join(lexemes, " <-> ") + ")"
+	}
+	if t.negate {
+		expr = "!" + expr
+	}
+	return expr, nil
+}
+
+// quoteTerm renders one word as a quoted tsquery operand, with a trailing *
+// turned into a prefix match.
+func quoteTerm(w string) string {
+	if strings.HasSuffix(w, "*") {
+		return quoteLexeme(strings.TrimSuffix(w, "*")) + ":*"
+	}
+	return quoteLexeme(w)
+}
+
+// quoteLexeme renders w as a quoted tsquery operand.
+func quoteLexeme(w string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range w {
+		switch r {
+		case '\'':
+			b.WriteString("''")
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	rs := []rune(input)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+
+		negate := false
+		if rs[i] == '-' {
+			if i+1 == len(rs) || unicode.IsSpace(rs[i+1]) {
+				// A lone "-" excludes nothing
+				i++
+				continue
+			}
+			negate = true
+			i++
+		}
+
+		if rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, errors.New("unterminated quoted phrase")
+			}
+			words, err := splitWords(string(rs[i+1 : end]))
+			if err != nil {
+				return nil, err
+			}
+			if len(words) == 0 {
+				return nil, errors.New("quoted phrase is empty")
+			}
+			tokens = append(tokens, token{words: words, phrase: true, negate: negate})
+			i = end + 1
+			continue
+		}
+
+		end := i
+		for end < len(rs) && !unicode.IsSpace(rs[end]) && rs[end] != '"' {
+			end++
+		}
+		word := string(rs[i:end])
+		i = end
+
+		if !negate && strings.EqualFold(word, "OR") {
+			tokens = append(tokens, token{or: true})
+			continue
+		}
+		words, err := splitWords(word)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token{words: words, negate: negate})
+	}
+	return tokens, nil
+}
+
+func splitWords(s string) ([]string, error) {
+	words := strings.Fields(s)
+	for _, w := range words {
+		if strings.Trim(w, "*") == "" {
+			return nil, fmt.Errorf("%q is not a search term; a prefix match needs at least one character before *", w)
+		}
+		if strings.Contains(strings.TrimSuffix(w, "*"), "*") {
+			return nil, fmt.Errorf("%q: * is only allowed at the end of a term", w)
+		}
+	}
+	return words, nil
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWebSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "words", input: "payment failed", want: "'payment' & 'failed'"},
+		{name: "phrase", input: `"payment failed"`, want: "('payment' <-> 'failed')"},
+		{name: "one-word phrase", input: `"payment"`, want: "'payment'"},
+		{name: "exclude word", input: "payment -refund", want: "'payment' & !'refund'"},
+		{name: "exclude phrase", input: `payment -"card declined"`, want: "'payment' & !('card' <-> 'declined')"},
+		{name: "or", input: "login OR signup", want: "('login' | 'signup')"},
+		{name: "or lowercase", input: "login or signup", want: "('login' | 'signup')"},
+		{name: "or binds tighter than and", input: "error login OR signup", want: "'error' & ('login' | 'signup')"},
+		{name: "or chain", input: "a OR b OR c", want: "('a' | 'b' | 'c')"},
+		{name: "excluded or is a word", input: "login -OR", want: "'login' & !'OR'"},
+		{name: "prefix", input: "log*", want: "'log':*"},
+		{name: "prefix in phrase", input: `"payment fail*"`, want: "('payment' <-> 'fail':*)"},
+		{name: "lone dash ignored", input: "payment - failed", want: "'payment' & 'failed'"},
+		{name: "quote and backslash escaped", input: `it's a\b`, want: `'it''s' & 'a\\b'`},
+		{name: "tsquery operators quoted", input: "a&b|c", want: "'a&b|c'"},
+		{name: "extra whitespace", input: "  payment \t failed  ", want: "'payment' & 'failed'"},
+		{name: "japanese", input: "ログイン 失敗", want: "'ログイン' & '失敗'"},
+		{name: "japanese phrase", input: `"ログイン 失敗"`, want: "('ログイン' <-> '失敗')"},
+		{name: "japanese prefix", input: "ログ*", want: "'ログ':*"},
+		{name: "accented", input: "café -naïve", want: "'café' & !'naïve'"},
+
+		{name: "empty", input: "", wantErr: "query is empty"},
+		{name: "only spaces", input: "   ", wantErr: "query is empty"},
+		{name: "unterminated quote", input: `"payment failed`, wantErr: "unterminated quoted phrase"},
+		{name: "empty phrase", input: `""`, wantErr: "quoted phrase is empty"},
+		{name: "leading or", input: "OR login", wantErr: "OR must be between two terms"},
+		{name: "trailing or", input: "login OR", wantErr: "OR must be between two terms"},
+		{name: "double or", input: "login OR OR signup", wantErr: "OR must be between two terms"},
+		{name: "only exclusions", input: "-refund -chargeback", wantErr: "not excluded"},
+		{name: "bare star", input: "*", wantErr: "needs at least one character before *"},
+		{name: "inner star", input: "lo*g", wantErr: "* is only allowed at the end"},
+		{name: "too many terms", input: strings.Repeat("a ", MaxQueryTerms+1), wantErr: "more than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebSearch(tt.input)
+			checkQuery(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func checkQuery(t *testing.T, got string, err error, want, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil {
+			t.Fatalf("got %q, want error containing %q", got, wantErr)
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("error %q does not contain %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
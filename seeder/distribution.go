@@ -0,0 +1,239 @@
+package seeder
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"log-project/utils"
+
+	"github.com/google/uuid"
+)
+
+// Distribution kinds for picking users, domains and actions.
+const (
+	Uniform = "uniform"
+	Zipf    = "zipf"
+	HotKey  = "hotkey"
+)
+
+// Time patterns for created_at.
+const (
+	TimeUniform = "uniform"
+	TimeDiurnal = "diurnal"
+	TimeBursts  = "bursts"
+)
+
+// Defaults for the distribution parameters.
+const (
+	DefaultZipfSkew   = 1.1
+	DefaultHotKeys    = 0.01 // 1% of keys are hot
+	DefaultHotShare   = 0.9  // and receive 90% of rows
+	DefaultRowsPerKey = 1000 // default user cardinality is RecordCount/1000
+	DefaultTimeWindow = 30 * 24 * time.Hour
+	defaultBurstShare = 0.3 // share of rows that land in bursts
+)
+
+// Distribution describes how often each key of a set (users, domains or
+// actions) is picked. The zero value is uniform.
+type Distribution struct {
+	Kind string // Uniform, Zipf or HotKey
+
+	// Skew is the Zipf exponent; it must be greater than 1. Larger values
+	// concentrate more rows on the first keys.
+	Skew float64
+
+	// HotKeys is the fraction of keys that are hot and HotShare the fraction
+	// of rows that go to them, for HotKey.
+	HotKeys  float64
+	HotShare float64
+}
+
+// Validate reports unknown kinds and out-of-range parameters.
+func (d Distribution) Validate() error {
+	switch d.Kind {
+	case "", Uniform:
+	case Zipf:
+		if d.Skew != 0 && d.Skew <= 1 {
+			return fmt.Errorf("zipf skew must be greater than 1, got %v", d.Skew)
+		}
+	case HotKey:
+		if d.HotKeys < 0 || d.HotKeys >= 1 {
+			return fmt.Errorf("hot_keys must be between 0 and 1, got %v", d.HotKeys)
+		}
+		if d.HotShare < 0 || d.HotShare >= 1 {
+			return fmt.Errorf("hot_share must be between 0 and 1, got %v", d.HotShare)
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q (want uniform, zipf or hotkey)", d.Kind)
+	}
+	return nil
+}
+
+// pick returns an index in [0,n). Low indexes are the popular ones for the
+// skewed kinds.
+func (d Distribution) pick(gen *utils.Generator, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	switch d.Kind {
+	case Zipf:
+		skew := d.Skew
+		if skew == 0 {
+			skew = DefaultZipfSkew
+		}
+		return int(gen.Zipf(skew, uint64(n-1)))
+	case HotKey:
+		hotKeys, hotShare := d.HotKeys, d.HotShare
+		if hotKeys == 0 {
+			hotKeys = DefaultHotKeys
+		}
+		if hotShare == 0 {
+			hotShare = DefaultHotShare
+		}
+		hot := int(math.Ceil(float64(n) * hotKeys))
+		if hot >= n {
+			return gen.Intn(n)
+		}
+		if gen.Float64() < hotShare {
+			return gen.Intn(hot)
+		}
+		return hot + gen.Intn(n-hot)
+	default:
+		return gen.Intn(n)
+	}
+}
+
+// diurnalAttempts caps how often a diurnal created_at is drawn again for
+// landing outside the time window.
+const diurnalAttempts = 64
+
+// hourWeights is the cumulative share of daily traffic by UTC hour for the
+// diurnal pattern: a trough around 02:00 and a peak around 14:00.
+var hourWeights = func() [24]float64 {
+	var cdf [24]float64
+	total := 0.0
+	for h := 0; h < 24; h++ {
+		total += 1 + 0.9*math.Sin(float64(h-8)*math.Pi/12)
+		cdf[h] = total
+	}
+	for h := range cdf {
+		cdf[h] /= total
+	}
+	return cdf
+}()
+
+type burst struct {
+	start time.Time
+	width time.Duration
+}
+
+// plan holds everything derived once per run that every batch samples from.
+// It is read-only once built, so generator workers share it.
+type plan struct {
+	users       []uuid.UUID
+	userDist    Distribution
+	domainDist  Distribution
+	actionDist  Distribution
+	timePattern string
+	windowStart time.Time
+	window      time.Duration
+	bursts      []burst
+}
+
+// Streams passed to utils.DeriveSeed for per-run data. Batches use stream
+// numbers >= 0.
+const (
+	userStream  = -1
+	burstStream = -2
+)
+
+func newPlan(opts Options, seed int64, base time.Time) (*plan, error) {
+	if err := opts.UserDistribution.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid user distribution: %w", err)
+	}
+	if err := opts.DomainDistribution.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid domain distribution: %w", err)
+	}
+	if err := opts.ActionDistribution.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid action distribution: %w", err)
+	}
+
+	users := opts.Users
+	if users <= 0 {
+		users = opts.RecordCount / DefaultRowsPerKey
+		if users < 1 {
+			users = 1
+		}
+	}
+	window := opts.TimeWindow
+	if window <= 0 {
+		window = DefaultTimeWindow
+	}
+
+	p := &plan{
+		users:       make([]uuid.UUID, users),
+		userDist:    opts.UserDistribution,
+		domainDist:  opts.DomainDistribution,
+		actionDist:  opts.ActionDistribution,
+		timePattern: opts.TimePattern,
+		windowStart: base.Add(-window),
+		window:      window,
+	}
+
+	gen := utils.NewGenerator(utils.DeriveSeed(seed, userStream), base)
+	for i := range p.users {
+		p.users[i] = gen.UUID()
+	}
+
+	switch opts.TimePattern {
+	case "", TimeUniform, TimeDiurnal:
+	case TimeBursts:
+		// Roughly one burst every five days, each 30 minutes to 2 hours long.
+		gen := utils.NewGenerator(utils.DeriveSeed(seed, burstStream), base)
+		n := int(window/(5*24*time.Hour)) + 1
+		for i := 0; i < n; i++ {
+			width := 30*time.Minute + time.Duration(gen.Intn(int(90*time.Minute/time.Second)))*time.Second
+			offset := time.Duration(gen.Float64() * float64(window-width))
+			p.bursts = append(p.bursts, burst{start: p.windowStart.Add(offset), width: width})
+		}
+	default:
+		return nil, fmt.Errorf("unknown time pattern %q (want uniform, diurnal or bursts)", opts.TimePattern)
+	}
+
+	return p, nil
+}
+
+func (p *plan) user(gen *utils.Generator) uuid.UUID {
+	return p.users[p.userDist.pick(gen, len(p.users))]
+}
+
+func (p *plan) createdAt(gen *utils.Generator) time.Time {
+	switch p.timePattern {
+	case TimeDiurnal:
+		// Days are whole UTC days, so the first and last may stick out of
+		// the window; times that land outside it are drawn again. Windows
+		// too short to hit that way fall back to uniform.
+		end := p.windowStart.Add(p.window)
+		first := p.windowStart.Truncate(24 * time.Hour)
+		days := int(end.Sub(first)/(24*time.Hour)) + 1
+		for attempt := 0; attempt < diurnalAttempts; attempt++ {
+			day := first.AddDate(0, 0, gen.Intn(days))
+			r := gen.Float64()
+			hour := 0
+			for hour < 23 && r >= hourWeights[hour] {
+				hour++
+			}
+			t := day.Add(time.Duration(hour)*time.Hour + time.Duration(gen.Intn(3600))*time.Second)
+			if !t.Before(p.windowStart) && t.Before(end) {
+				return t
+			}
+		}
+	case TimeBursts:
+		if gen.Float64() < defaultBurstShare {
+			b := p.bursts[gen.Intn(len(p.bursts))]
+			return b.start.Add(time.Duration(gen.Float64() * float64(b.width)))
+		}
+	}
+	return p.windowStart.Add(time.Duration(gen.Float64() * float64(p.window)))
+}
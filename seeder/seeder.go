@@ -0,0 +1,495 @@
+package seeder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"log-project/internal/db"
+	"log-project/models"
+	"log-project/search"
+	"log-project/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultBatchSize is the number of rows sent per COPY FROM round-trip.
+const DefaultBatchSize = 1000
+
+// DefaultWriters is the number of concurrent COPY connections used when
+// Options.Writers is not set.
+const DefaultWriters = 4
+
+// Options describes the dataset to generate.
+type Options struct {
+	RecordCount int
+	ContentSize string
+	BatchSize   int
+
+	// Profile, when set, replaces ContentSize with a custom content shape.
+	Profile *utils.Profile
+
+	// TargetRowBytes pads every document with a "padding" field so that its
+	// JSON text is about this many bytes. Documents already larger are left
+	// as they are.
+	TargetRowBytes int
+
+	// Generators is the number of goroutines building batches (JSON content
+	// generation is CPU bound). Defaults to runtime.NumCPU().
+	Generators int
+
+	// Writers is the number of goroutines running COPY FROM, each on its own
+	// pooled connection. Defaults to DefaultWriters, capped at the pool size.
+	Writers int
+
+	// Users is the number of distinct user IDs. Defaults to RecordCount /
+	// DefaultRowsPerKey (at least one).
+	Users int
+
+	// UserDistribution, DomainDistribution and ActionDistribution choose how
+	// rows are spread over users, domains and actions. The zero value is
+	// uniform.
+	UserDistribution   Distribution
+	DomainDistribution Distribution
+	ActionDistribution Distribution
+
+	// TimePattern shapes created_at within TimeWindow before BaseTime:
+	// TimeUniform (default), TimeDiurnal or TimeBursts. TimeWindow defaults to
+	// DefaultTimeWindow.
+	TimePattern string
+	TimeWindow  time.Duration
+
+	// Seed makes the dataset reproducible: the same seed, record count,
+	// content size and batch size produce byte-identical rows. Zero picks a
+	// random seed, which is reported back in Result.Seed.
+	Seed int64
+
+	// BaseTime anchors generated timestamps. When zero it defaults to
+	// utils.DeterministicBaseTime for an explicit Seed and time.Now()
+	// otherwise.
+	BaseTime time.Time
+}
+
+// Progress is reported after every batch has been copied. With more than one
+// writer batches complete out of order, so Batch counts completed batches
+// rather than naming a specific one.
+type Progress struct {
+	Batch        int
+	TotalBatches int
+	BatchRows    int64
+	Inserted     int64
+	Total        int64
+}
+
+// Percent returns how much of the dataset has been inserted so far.
+func (p Progress) Percent() float64 {
+	if p.TotalBatches == 0 {
+		return 100
+	}
+	return float64(p.Batch) / float64(p.TotalBatches) * 100
+}
+
+// Result summarizes a completed seeding run.
+type Result struct {
+	Seed     int64
+	Inserted int64
+	Bytes    int64 // Total size of the generated JSON content
+	Duration time.Duration
+	Generate StageStats
+	Write    StageStats
+}
+
+// StageStats describes one stage of the generate -> COPY pipeline. Busy is
+// the time spent working summed over all of the stage's workers, so it
+// excludes time spent waiting on the other stage.
+type StageStats struct {
+	Workers int
+	Rows    int64
+	Busy    time.Duration
+}
+
+// RecordsPerSecond is the rate the stage sustains while busy, i.e. what the
+// whole pipeline could reach if this stage were the only bottleneck.
+func (s StageStats) RecordsPerSecond() float64 {
+	if s.Busy <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / (s.Busy.Seconds() / float64(s.Workers))
+}
+
+// Utilization is the fraction of wall time the stage's workers were busy.
+func (s StageStats) Utilization(wall time.Duration) float64 {
+	if wall <= 0 || s.Workers == 0 {
+		return 0
+	}
+	return s.Busy.Seconds() / (wall.Seconds() * float64(s.Workers))
+}
+
+// RecordsPerSecond returns the overall insert throughput.
+func (r Result) RecordsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Inserted) / r.Duration.Seconds()
+}
+
+// Run generates opts.RecordCount sample logs and inserts them with COPY FROM
+// in batches. Generation and COPY run as a pipeline: opts.Generators workers
+// build batches while opts.Writers workers copy them, each on its own pooled
+// connection. onProgress, if non-nil, is called after every batch from a
+// single goroutine at a time. Cancelling ctx stops the run; rows already
+// copied are kept.
+func Run(ctx context.Context, pool *pgxpool.Pool, opts Options, onProgress func(Progress)) (Result, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	totalBatches := (opts.RecordCount + batchSize - 1) / batchSize
+
+	generators := opts.Generators
+	if generators <= 0 {
+		generators = runtime.NumCPU()
+	}
+	writers := opts.Writers
+	if writers <= 0 {
+		writers = DefaultWriters
+	}
+	if maxConns := int(pool.Config().MaxConns); writers > maxConns {
+		writers = maxConns
+	}
+
+	seed := opts.Seed
+	base := opts.BaseTime
+	if base.IsZero() {
+		if seed != 0 {
+			base = utils.DeterministicBaseTime
+		} else {
+			base = time.Now()
+		}
+	}
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+
+	pl, err := newPlan(opts, seed, base)
+	if err != nil {
+		return Result{Seed: seed}, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		result   = Result{
+			Seed:     seed,
+			Generate: StageStats{Workers: generators},
+			Write:    StageStats{Workers: writers},
+		}
+		completed int
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	start := time.Now()
+
+	batchIndexes := make(chan int)
+	go func() {
+		defer close(batchIndexes)
+		for batch := 0; batch < totalBatches; batch++ {
+			select {
+			case batchIndexes <- batch:
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+
+	type generatedBatch struct {
+		index  int
+		params []db.BulkInsertLogsParams
+	}
+	batches := make(chan generatedBatch, writers*2)
+
+	var genWG sync.WaitGroup
+	for w := 0; w < generators; w++ {
+		genWG.Add(1)
+		go func() {
+			defer genWG.Done()
+			for batch := range batchIndexes {
+				currentBatchSize := batchSize
+				if batch == totalBatches-1 {
+					currentBatchSize = opts.RecordCount - (batch * batchSize)
+				}
+
+				genStart := time.Now()
+				gen := utils.NewGenerator(utils.DeriveSeed(seed, int64(batch)), base)
+				params, err := generateBatch(gen, pl, opts, currentBatchSize)
+				if err != nil {
+					fail(err)
+					return
+				}
+				mu.Lock()
+				result.Generate.Rows += int64(len(params))
+				result.Generate.Busy += time.Since(genStart)
+				mu.Unlock()
+
+				select {
+				case batches <- generatedBatch{index: batch, params: params}:
+				case <-runCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		genWG.Wait()
+		close(batches)
+	}()
+
+	var writeWG sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		writeWG.Add(1)
+		go func() {
+			defer writeWG.Done()
+
+			conn, err := pool.Acquire(runCtx)
+			if err != nil {
+				fail(fmt.Errorf("failed to acquire connection: %w", err))
+				return
+			}
+			defer conn.Release()
+			queries := db.New(conn)
+
+			for b := range batches {
+				copyStart := time.Now()
+				// Use CopyFrom for bulk insert
+				rowsInserted, err := queries.BulkInsertLogs(runCtx, b.params)
+				if err != nil {
+					fail(fmt.Errorf("failed to insert batch %d: %w", b.index+1, err))
+					return
+				}
+				var batchBytes int64
+				for _, p := range b.params {
+					batchBytes += int64(len(p.Content))
+				}
+
+				mu.Lock()
+				result.Write.Rows += rowsInserted
+				result.Write.Busy += time.Since(copyStart)
+				result.Inserted += rowsInserted
+				result.Bytes += batchBytes
+				completed++
+				if onProgress != nil {
+					onProgress(Progress{
+						Batch:        completed,
+						TotalBatches: totalBatches,
+						BatchRows:    rowsInserted,
+						Inserted:     result.Inserted,
+						Total:        int64(opts.RecordCount),
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	writeWG.Wait()
+	result.Duration = time.Since(start)
+
+	// A cancelled caller context surfaces as whatever error the interrupted
+	// COPY returned; report the cancellation itself instead.
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, firstErr
+}
+
+func generateBatch(gen *utils.Generator, pl *plan, opts Options, size int) ([]db.BulkInsertLogsParams, error) {
+	params := make([]db.BulkInsertLogsParams, size)
+
+	for i := 0; i < size; i++ {
+		userID := pl.user(gen)
+		domain := domains[pl.domainDist.pick(gen, len(domains))]
+		action := actions[pl.actionDist.pick(gen, len(actions))]
+		createdAt := pl.createdAt(gen)
+		contentBytes, err := generateContent(gen, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		params[i] = db.BulkInsertLogsParams{
+			UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+			Domain:     domain,
+			Action:     action,
+			Content:    contentBytes,
+			ContentCjk: search.ContentCJK(contentBytes),
+			CreatedAt: pgtype.Timestamptz{
+				Time:  createdAt,
+				Valid: true,
+			},
+		}
+	}
+
+	return params, nil
+}
+
+// generateContent builds one JSON document for opts, padded to
+// opts.TargetRowBytes when set.
+func generateContent(gen *utils.Generator, opts Options) ([]byte, error) {
+	var content models.Content
+	if opts.Profile != nil {
+		content = gen.GenerateProfileContent(opts.Profile)
+	} else {
+		content = gen.GenerateSampleContent(opts.ContentSize)
+	}
+
+	// Convert content to JSON bytes
+	contentBytes, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal content: %w", err)
+	}
+
+	if opts.TargetRowBytes > 0 {
+		contentBytes = pad(gen, contentBytes, opts.TargetRowBytes)
+	}
+	return contentBytes, nil
+}
+
+// pad appends a "padding" member to the JSON object doc so that it grows to
+// target bytes. The object is spliced rather than re-marshalled.
+func pad(gen *utils.Generator, doc []byte, target int) []byte {
+	const (
+		member      = `"padding":"`
+		emptyExtra  = len(member) + 1 // {"padding":""}
+		nonEmptyAdd = len(member) + 2 // ,"padding":""
+	)
+	empty := len(doc) == 2
+	extra := nonEmptyAdd
+	if empty {
+		extra = emptyExtra
+	}
+	n := target - len(doc) - extra
+	if n <= 0 {
+		return doc
+	}
+
+	out := make([]byte, 0, target)
+	out = append(out, doc[:len(doc)-1]...)
+	if !empty {
+		out = append(out, ',')
+	}
+	out = append(out, member...)
+	out = append(out, gen.Padding(n)...)
+	out = append(out, '"', '}')
+	return out
+}
+
+// sampleStream seeds the rows EstimateRecordCount measures.
+const sampleStream = -3
+
+// Bounds on each of the two samples EstimateRecordCount inserts: enough rows
+// to fill several heap and index pages, without copying very large documents
+// thousands of times.
+const (
+	sampleMaxRows  = 2000
+	sampleMinRows  = 10
+	sampleMaxBytes = 16 << 20
+)
+
+// EstimateRecordCount returns how many rows shaped like opts take up about
+// totalBytes on disk, as pg_total_relation_size counts them: heap, TOAST and
+// every index on logs, including the generated tsvector columns. It copies
+// two equal samples into a temporary copy of logs and divides the growth
+// from the second by its row count, so fixed costs like index metapages are
+// left out. Nothing is written to logs itself.
+func EstimateRecordCount(ctx context.Context, pool *pgxpool.Pool, opts Options, totalBytes int64) (int, error) {
+	base := opts.BaseTime
+	if base.IsZero() {
+		base = utils.DeterministicBaseTime
+	}
+	seed := utils.DeriveSeed(opts.Seed, sampleStream)
+	pl, err := newPlan(opts, seed, base)
+	if err != nil {
+		return 0, err
+	}
+	gen := utils.NewGenerator(seed, base)
+
+	first, err := generateBatch(gen, pl, opts, sampleMinRows)
+	if err != nil {
+		return 0, err
+	}
+	var firstBytes int
+	for _, p := range first {
+		firstBytes += len(p.Content)
+	}
+	rows := sampleMaxBytes / (firstBytes/sampleMinRows + 1)
+	rows = max(sampleMinRows, min(rows, sampleMaxRows))
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if _, err := tx.Exec(ctx, "CREATE TEMP TABLE logs_size_sample (LIKE logs INCLUDING ALL) ON COMMIT DROP"); err != nil {
+		return 0, fmt.Errorf("failed to create sample table: %w", err)
+	}
+	var sizes [2]int64
+	for i := range sizes {
+		sample, err := generateBatch(gen, pl, opts, rows)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.CopyFrom(ctx, pgx.Identifier{"logs_size_sample"},
+			[]string{"user_id", "domain", "action", "content", "content_cjk", "created_at"},
+			pgx.CopyFromSlice(len(sample), func(i int) ([]interface{}, error) {
+				p := sample[i]
+				return []interface{}{p.UserID, p.Domain, p.Action, p.Content, p.ContentCjk, p.CreatedAt}, nil
+			}))
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert sample: %w", err)
+		}
+		if err := tx.QueryRow(ctx, "SELECT pg_total_relation_size('logs_size_sample')").Scan(&sizes[i]); err != nil {
+			return 0, fmt.Errorf("failed to measure sample: %w", err)
+		}
+	}
+
+	growth := sizes[1] - sizes[0]
+	if growth <= 0 {
+		return 0, fmt.Errorf("sample of %d rows did not grow the table", rows)
+	}
+	perRow := float64(growth) / float64(rows)
+	return int(math.Ceil(float64(totalBytes) / perRow)), nil
+}
+
+// domains and actions are ordered by popularity for the skewed distributions:
+// the first entries are the ones Zipf and hot-key favour.
+var domains = []string{
+	"example.com", "test.org", "demo.net", "app.io", "api.service.com",
+	"web.portal.com", "mobile.app.net", "admin.system.org", "user.platform.io",
+	"data.analytics.com", "payments.service.net", "content.media.org", "social.platform.io",
+}
+
+var actions = []string{
+	"user_login", "user_logout", "page_view", "button_click", "form_submit",
+	"file_upload", "file_download", "search_query", "filter_apply", "sort_change",
+	"create_record", "update_record", "delete_record", "export_data", "import_data",
+	"send_message", "receive_message", "share_content", "like_post", "comment_post",
+	"subscribe", "unsubscribe", "follow_user", "unfollow_user", "report_issue",
+	"request_feature", "update_settings", "change_password", "reset_password",
+}
@@ -24,6 +24,25 @@ ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: ListLogsWithFilters :many
+-- Filters go through log_matches (migration 00006), shared with the other
+-- list, count and export queries; NULL arguments disable their filter.
+-- field_path (a jsonpath from search.ParseField) limits content matches to the
+-- values it selects. It is checked on the rows the content_tsv, simple or
+-- content_cjk_tsv predicates already matched through their indexes.
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, sqlc.narg('simple_query')::text, sqlc.narg('cjk_query')::text,
+        NULL::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListLogsWithFiltersExpression :many
+-- Same as ListLogsWithFilters but matches against the idx_logs_content_fts
+-- expression index instead of the stored content_tsv column.
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
 WHERE 
@@ -35,26 +54,56 @@ WHERE
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
+-- name: ListLogsWithFiltersKeyset :many
+-- Keyset pagination over (created_at, id): returns the rows that come after
+-- the (after_created_at, after_id) cursor in created_at DESC, id DESC order,
+-- or the first page when the cursor is NULL.
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, sqlc.narg('simple_query')::text, sqlc.narg('cjk_query')::text,
+        NULL::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text) AND
+    (sqlc.narg('after_created_at')::timestamptz IS NULL OR (created_at, id) < (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
 -- name: CountLogs :one
 SELECT COUNT(*) FROM logs;
 
 -- name: CountLogsWithFilters :one
 SELECT COUNT(*) FROM logs
-WHERE 
-    (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id')) AND
-    (sqlc.narg('domain')::text IS NULL OR domain = sqlc.narg('domain')) AND
-    (sqlc.narg('created_at_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_at_from')) AND
-    (sqlc.narg('created_at_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_at_to')) AND
-    (sqlc.narg('content_search')::text IS NULL OR to_tsvector('english', content::text) @@ plainto_tsquery('english', sqlc.narg('content_search')));
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, sqlc.narg('simple_query')::text, sqlc.narg('cjk_query')::text,
+        NULL::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text);
+
+-- name: CountLogsWithFiltersCapped :one
+-- Counts at most max_count matching rows, so the scan stops early on large
+-- result sets.
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM logs
+    WHERE
+        log_matches(logs,
+            sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+            sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, sqlc.narg('simple_query')::text, sqlc.narg('cjk_query')::text,
+            NULL::text, sqlc.narg('field_path')::text,
+            sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
+    LIMIT sqlc.arg('max_count')
+) capped;
 
 -- name: CreateLog :one
-INSERT INTO logs (user_id, domain, action, content, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO logs (user_id, domain, action, content, content_cjk, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING id, user_id, domain, action, content, created_at;
 
 -- name: BulkInsertLogs :copyfrom
-INSERT INTO logs (user_id, domain, action, content, created_at)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO logs (user_id, domain, action, content, content_cjk, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: TruncateLogs :exec
 TRUNCATE TABLE logs RESTART IDENTITY CASCADE;
@@ -63,22 +112,121 @@ TRUNCATE TABLE logs RESTART IDENTITY CASCADE;
 DELETE FROM logs WHERE id = $1;
 
 -- name: SearchLogsPartial :many
+-- field_path limits matches to the values it selects, as in
+-- ListLogsWithFilters; the trigram index still finds the candidate rows.
 SELECT id, user_id, domain, action, content, created_at
 FROM logs
-WHERE 
-    (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id')) AND
-    (sqlc.narg('domain')::text IS NULL OR domain = sqlc.narg('domain')) AND
-    (sqlc.narg('created_at_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_at_from')) AND
-    (sqlc.narg('created_at_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_at_to')) AND
-    content::text ILIKE '%' || sqlc.narg('search_term')::text || '%'
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        sqlc.narg('search_term')::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
 ORDER BY created_at DESC
 LIMIT sqlc.narg('limit') OFFSET sqlc.narg('offset');
 
+-- name: SearchLogsPartialKeyset :many
+-- Keyset variant of SearchLogsPartial; see ListLogsWithFiltersKeyset.
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        sqlc.narg('search_term')::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text) AND
+    (sqlc.narg('after_created_at')::timestamptz IS NULL OR (created_at, id) < (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.narg('limit');
+
+-- name: SearchLogsRanked :many
+-- Full-text matches ordered by relevance, with ts_rank_cd (cover density)
+-- instead of ts_rank when rank_cd is set. The query is content_query (tsquery
+-- syntax) when given, else content_search (plain text). ts_headline re-parses
+-- the document, so it only runs on the rows of the requested page.
+SELECT id, user_id, domain, action, content, created_at, rank,
+    ts_headline('english', content::text, COALESCE(to_tsquery('english', sqlc.narg('content_query')), plainto_tsquery('english', sqlc.narg('content_search'))),
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" … "')::text AS headline
+FROM (
+    SELECT id, user_id, domain, action, content, created_at,
+        (CASE WHEN sqlc.arg('rank_cd')::boolean
+            THEN ts_rank_cd(content_tsv, COALESCE(to_tsquery('english', sqlc.narg('content_query')), plainto_tsquery('english', sqlc.narg('content_search'))))
+            ELSE ts_rank(content_tsv, COALESCE(to_tsquery('english', sqlc.narg('content_query')), plainto_tsquery('english', sqlc.narg('content_search'))))
+        END)::real AS rank
+    FROM logs
+    WHERE
+        log_matches(logs,
+            sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+            sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, NULL::text, NULL::text,
+            NULL::text, sqlc.narg('field_path')::text,
+            sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
+    ORDER BY rank DESC, created_at DESC, id DESC
+    LIMIT $1 OFFSET $2
+) ranked
+ORDER BY rank DESC, created_at DESC, id DESC;
+
 -- name: CountLogsPartial :one
 SELECT COUNT(*) FROM logs
-WHERE 
-    (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id')) AND
-    (sqlc.narg('domain')::text IS NULL OR domain = sqlc.narg('domain')) AND
-    (sqlc.narg('created_at_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_at_from')) AND
-    (sqlc.narg('created_at_to')::timestamptz IS NULL OR created_at <= sqlc.narg('created_at_to')) AND
-    content::text ILIKE '%' || sqlc.narg('search_term')::text || '%';
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        NULL::text, NULL::text, NULL::text, NULL::text,
+        sqlc.narg('search_term')::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text);
+
+-- name: CountLogsPartialCapped :one
+-- Capped variant of CountLogsPartial; see CountLogsWithFiltersCapped.
+SELECT COUNT(*) FROM (
+    SELECT 1 FROM logs
+    WHERE
+        log_matches(logs,
+            sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+            NULL::text, NULL::text, NULL::text, NULL::text,
+            sqlc.narg('search_term')::text, sqlc.narg('field_path')::text,
+            sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
+    LIMIT sqlc.arg('max_count')
+) capped;
+
+-- name: ExportLogs :many
+-- Every row /logs (content_like) and /search/partial (search_term) match for
+-- the filters, without a LIMIT. The export handler declares a server-side
+-- cursor over this query's SQL rather than calling it.
+SELECT id, user_id, domain, action, content, created_at
+FROM logs
+WHERE
+    log_matches(logs,
+        sqlc.narg('user_id')::uuid, sqlc.narg('domain')::text, sqlc.narg('created_at_from')::timestamptz, sqlc.narg('created_at_to')::timestamptz,
+        sqlc.narg('content_search')::text, sqlc.narg('content_query')::text, sqlc.narg('simple_query')::text, sqlc.narg('cjk_query')::text,
+        sqlc.narg('search_term')::text, sqlc.narg('field_path')::text,
+        sqlc.narg('json_contains')::jsonb, sqlc.narg('json_match')::text, sqlc.narg('json_path')::text)
+ORDER BY created_at DESC;
+
+-- name: ListLogContentAfter :many
+-- One batch of rows in id order, starting after after_id (or at the first row
+-- when it is NULL), for jobs that walk the whole table.
+SELECT id, content
+FROM logs
+WHERE sqlc.narg('after_id')::uuid IS NULL OR id > sqlc.narg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('batch_size');
+
+-- name: UpdateContentCJK :execrows
+-- Sets content_cjk for a batch of rows; an empty string stores NULL. Rows
+-- that already hold the value are not rewritten.
+UPDATE logs
+SET content_cjk = NULLIF(batch.content_cjk, '')
+FROM (
+    SELECT unnest(sqlc.arg('ids')::uuid[]) AS id, unnest(sqlc.arg('content_cjk')::text[]) AS content_cjk
+) AS batch
+WHERE logs.id = batch.id AND logs.content_cjk IS DISTINCT FROM NULLIF(batch.content_cjk, '');
+
+-- name: GetFTSStorageStats :one
+SELECT
+    COALESCE(pg_relation_size(to_regclass('idx_logs_content_fts')), 0)::bigint AS expression_index_bytes,
+    COALESCE(pg_relation_size(to_regclass('idx_logs_content_tsv')), 0)::bigint AS stored_index_bytes,
+    COALESCE(SUM(pg_column_size(content_tsv)), 0)::bigint AS stored_column_bytes
+FROM logs;
+
+-- name: ValidateJSONPath :exec
+-- Fails with a syntax error when path is not a valid jsonpath.
+SELECT sqlc.arg('path')::text::jsonpath;
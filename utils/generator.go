@@ -10,86 +10,161 @@ import (
 	"github.com/google/uuid"
 )
 
-func GenerateSampleContent(size string) models.Content {
-	// rand.Seed is automatically initialized in Go 1.20+
+// DeterministicBaseTime anchors generated timestamps when an explicit seed is
+// used, so the same seed yields the same dataset regardless of when it runs.
+var DeterministicBaseTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator produces sample log content from its own random source. Two
+// generators created with the same seed and base time produce identical
+// output. A Generator is not safe for concurrent use.
+type Generator struct {
+	rng  *rand.Rand
+	base time.Time
+}
+
+// NewGenerator returns a Generator seeded with seed. Generated timestamps are
+// relative to base instead of the wall clock.
+func NewGenerator(seed int64, base time.Time) *Generator {
+	return &Generator{
+		rng:  rand.New(rand.NewSource(seed)),
+		base: base,
+	}
+}
+
+// DeriveSeed returns an independent seed for the given stream (e.g. a batch
+// number) so that streams can be generated in any order, or in parallel, and
+// still be reproducible. It uses the SplitMix64 finalizer.
+func DeriveSeed(seed, stream int64) int64 {
+	z := uint64(seed) + uint64(stream+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return int64(z ^ (z >> 31))
+}
+
+// Intn returns a non-negative pseudo-random number in [0,n).
+func (g *Generator) Intn(n int) int {
+	return g.rng.Intn(n)
+}
+
+// Float64 returns a pseudo-random number in [0.0,1.0).
+func (g *Generator) Float64() float64 {
+	return g.rng.Float64()
+}
+
+// Zipf returns a Zipf-distributed value in [0,imax] with exponent s > 1, so
+// that 0 is the most frequent value.
+func (g *Generator) Zipf(s float64, imax uint64) uint64 {
+	return rand.NewZipf(g.rng, s, 1, imax).Uint64()
+}
+
+// paddingChars excludes characters that JSON would escape, so n padding
+// characters are exactly n bytes of JSON.
+const paddingChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// Padding returns n random characters. They are random rather than repeated so
+// that TOAST compression cannot shrink padded rows back down.
+func (g *Generator) Padding(n int) string {
+	b := make([]byte, n)
+	g.rng.Read(b)
+	for i := range b {
+		b[i] = paddingChars[b[i]&63]
+	}
+	return string(b)
+}
+
+// UUID returns a random (version 4) UUID drawn from the generator's source.
+func (g *Generator) UUID() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(g.rng)
+	if err != nil {
+		// rand.Rand.Read never fails
+		panic(err)
+	}
+	return id
+}
+
+// Now returns the generator's base time, used in place of time.Now().
+func (g *Generator) Now() time.Time {
+	return g.base
+}
 
+func (g *Generator) GenerateSampleContent(size string) models.Content {
 	switch size {
 	case "small":
-		return generateSmallContent()
+		return g.generateSmallContent()
 	case "medium":
-		return generateMediumContent()
+		return g.generateMediumContent()
 	case "large":
-		return generateLargeContent()
+		return g.generateLargeContent()
 	default:
-		return generateSmallContent()
+		return g.generateSmallContent()
 	}
 }
 
-func generateSmallContent() models.Content {
+func (g *Generator) generateSmallContent() models.Content {
 	return models.Content{
-		"event_id":    uuid.New().String(),
-		"session_id":  fmt.Sprintf("sess_%d", rand.Intn(100000)),
-		"ip_address":  fmt.Sprintf("192.168.%d.%d", rand.Intn(255), rand.Intn(255)),
-		"user_agent":  getRandomUserAgent(),
-		"timestamp":   time.Now().Unix(),
-		"action_type": getRandomAction(),
-		"status":      getRandomStatus(),
-		"duration":    rand.Intn(5000) + 100,
-		"device_id":   fmt.Sprintf("device_%d", rand.Intn(10000)),
+		"event_id":    g.UUID().String(),
+		"session_id":  fmt.Sprintf("sess_%d", g.rng.Intn(100000)),
+		"ip_address":  fmt.Sprintf("192.168.%d.%d", g.rng.Intn(255), g.rng.Intn(255)),
+		"user_agent":  g.getRandomUserAgent(),
+		"timestamp":   g.base.Unix(),
+		"action_type": g.getRandomAction(),
+		"status":      g.getRandomStatus(),
+		"duration":    g.rng.Intn(5000) + 100,
+		"device_id":   fmt.Sprintf("device_%d", g.rng.Intn(10000)),
 	}
 }
 
-func generateMediumContent() models.Content {
-	content := generateSmallContent()
+func (g *Generator) generateMediumContent() models.Content {
+	content := g.generateSmallContent()
 
 	// Add more fields
 	additionalFields := models.Content{
-		"request_id":      uuid.New().String(),
-		"correlation_id":  fmt.Sprintf("corr_%d", rand.Intn(1000000)),
-		"source_ip":       fmt.Sprintf("10.0.%d.%d", rand.Intn(255), rand.Intn(255)),
-		"destination_ip":  fmt.Sprintf("172.16.%d.%d", rand.Intn(255), rand.Intn(255)),
-		"protocol":        getRandomProtocol(),
-		"port":            rand.Intn(65535),
-		"bytes_sent":      rand.Intn(1000000),
-		"bytes_received":  rand.Intn(1000000),
-		"latency":         rand.Intn(1000),
-		"error_code":      getRandomErrorCode(),
-		"retry_count":     rand.Intn(5),
-		"cache_hit":       rand.Intn(2) == 1,
-		"compression":     rand.Intn(2) == 1,
-		"encrypted":       rand.Intn(2) == 1,
-		"region":          getRandomRegion(),
-		"datacenter":      fmt.Sprintf("dc-%d", rand.Intn(10)+1),
-		"service_version": fmt.Sprintf("v%d.%d.%d", rand.Intn(5)+1, rand.Intn(10), rand.Intn(20)),
-		"build_number":    rand.Intn(10000),
-		"environment":     getRandomEnvironment(),
-		"tenant_id":       uuid.New().String(),
-		"org_id":          fmt.Sprintf("org_%d", rand.Intn(1000)),
-		"team_id":         fmt.Sprintf("team_%d", rand.Intn(100)),
-		"project_id":      fmt.Sprintf("proj_%d", rand.Intn(50)),
-		"feature_flag":    getRandomFeatureFlag(),
-		"ab_test":         fmt.Sprintf("test_%d", rand.Intn(100)),
-		"experiment_id":   uuid.New().String(),
-		"segment":         getRandomSegment(),
-		"cohort":          fmt.Sprintf("cohort_%d", rand.Intn(10)+1),
-		"tier":            getRandomTier(),
-		"plan":            getRandomPlan(),
-		"quota":           rand.Intn(10000),
-		"usage":           rand.Intn(1000),
-		"limit":           rand.Intn(5000),
-		"remaining":       rand.Intn(1000),
-		"renewal_date":    time.Now().AddDate(0, rand.Intn(12), 0).Format("2006-01-02"),
-		"last_login":      time.Now().Add(-time.Duration(rand.Intn(86400)) * time.Second).Format(time.RFC3339),
-		"first_login":     time.Now().Add(-time.Duration(rand.Intn(86400*30)) * time.Second).Format(time.RFC3339),
-		"session_count":   rand.Intn(100),
-		"total_sessions":  rand.Intn(1000),
-		"country":         getRandomCountry(),
-		"city":            getRandomCity(),
-		"timezone":        getRandomTimezone(),
-		"language":        getRandomLanguage(),
-		"currency":        getRandomCurrency(),
-		"description":     generateJapaneseString(rand.Intn(100) + 20),
-		"notes":           generateJapaneseString(rand.Intn(50) + 10),
+		"request_id":      g.UUID().String(),
+		"correlation_id":  fmt.Sprintf("corr_%d", g.rng.Intn(1000000)),
+		"source_ip":       fmt.Sprintf("10.0.%d.%d", g.rng.Intn(255), g.rng.Intn(255)),
+		"destination_ip":  fmt.Sprintf("172.16.%d.%d", g.rng.Intn(255), g.rng.Intn(255)),
+		"protocol":        g.getRandomProtocol(),
+		"port":            g.rng.Intn(65535),
+		"bytes_sent":      g.rng.Intn(1000000),
+		"bytes_received":  g.rng.Intn(1000000),
+		"latency":         g.rng.Intn(1000),
+		"error_code":      g.getRandomErrorCode(),
+		"retry_count":     g.rng.Intn(5),
+		"cache_hit":       g.rng.Intn(2) == 1,
+		"compression":     g.rng.Intn(2) == 1,
+		"encrypted":       g.rng.Intn(2) == 1,
+		"region":          g.getRandomRegion(),
+		"datacenter":      fmt.Sprintf("dc-%d", g.rng.Intn(10)+1),
+		"service_version": fmt.Sprintf("v%d.%d.%d", g.rng.Intn(5)+1, g.rng.Intn(10), g.rng.Intn(20)),
+		"build_number":    g.rng.Intn(10000),
+		"environment":     g.getRandomEnvironment(),
+		"tenant_id":       g.UUID().String(),
+		"org_id":          fmt.Sprintf("org_%d", g.rng.Intn(1000)),
+		"team_id":         fmt.Sprintf("team_%d", g.rng.Intn(100)),
+		"project_id":      fmt.Sprintf("proj_%d", g.rng.Intn(50)),
+		"feature_flag":    g.getRandomFeatureFlag(),
+		"ab_test":         fmt.Sprintf("test_%d", g.rng.Intn(100)),
+		"experiment_id":   g.UUID().String(),
+		"segment":         g.getRandomSegment(),
+		"cohort":          fmt.Sprintf("cohort_%d", g.rng.Intn(10)+1),
+		"tier":            g.getRandomTier(),
+		"plan":            g.getRandomPlan(),
+		"quota":           g.rng.Intn(10000),
+		"usage":           g.rng.Intn(1000),
+		"limit":           g.rng.Intn(5000),
+		"remaining":       g.rng.Intn(1000),
+		"renewal_date":    g.base.AddDate(0, g.rng.Intn(12), 0).Format("2006-01-02"),
+		"last_login":      g.base.Add(-time.Duration(g.rng.Intn(86400)) * time.Second).Format(time.RFC3339),
+		"first_login":     g.base.Add(-time.Duration(g.rng.Intn(86400*30)) * time.Second).Format(time.RFC3339),
+		"session_count":   g.rng.Intn(100),
+		"total_sessions":  g.rng.Intn(1000),
+		"country":         g.getRandomCountry(),
+		"city":            g.getRandomCity(),
+		"timezone":        g.getRandomTimezone(),
+		"language":        g.getRandomLanguage(),
+		"currency":        g.getRandomCurrency(),
+		"description":     g.generateJapaneseString(g.rng.Intn(100) + 20),
+		"notes":           g.generateJapaneseString(g.rng.Intn(50) + 10),
 	}
 
 	for k, v := range additionalFields {
@@ -99,33 +174,33 @@ func generateMediumContent() models.Content {
 	return content
 }
 
-func generateLargeContent() models.Content {
-	content := generateMediumContent()
+func (g *Generator) generateLargeContent() models.Content {
+	content := g.generateMediumContent()
 
 	// Add many more fields for large content
 	for i := 0; i < 500; i++ {
-		content[fmt.Sprintf("field_%d", i)] = fmt.Sprintf("value_%d_%s", rand.Intn(100000), generateJapaneseString(5))
+		content[fmt.Sprintf("field_%d", i)] = fmt.Sprintf("value_%d_%s", g.rng.Intn(100000), g.generateJapaneseString(5))
 	}
 
 	// Add Japanese content fields
 	for i := 0; i < 200; i++ {
-		content[fmt.Sprintf("japanese_field_%d", i)] = generateJapaneseString(rand.Intn(100) + 20)
+		content[fmt.Sprintf("japanese_field_%d", i)] = g.generateJapaneseString(g.rng.Intn(100) + 20)
 	}
 
 	// Add nested objects
 	for i := 0; i < 100; i++ {
 		nested := make(models.Content)
 		for j := 0; j < 10; j++ {
-			nested[fmt.Sprintf("nested_field_%d", j)] = fmt.Sprintf("nested_value_%d_%s", rand.Intn(1000), generateJapaneseString(5))
+			nested[fmt.Sprintf("nested_field_%d", j)] = fmt.Sprintf("nested_value_%d_%s", g.rng.Intn(1000), g.generateJapaneseString(5))
 		}
 		content[fmt.Sprintf("nested_obj_%d", i)] = nested
 	}
 
 	// Add arrays
 	for i := 0; i < 50; i++ {
-		arr := make([]string, rand.Intn(20)+1)
+		arr := make([]string, g.rng.Intn(20)+1)
 		for j := range arr {
-			arr[j] = fmt.Sprintf("array_item_%d_%d_%s", i, j, generateJapaneseString(5))
+			arr[j] = fmt.Sprintf("array_item_%d_%d_%s", i, j, g.generateJapaneseString(5))
 		}
 		content[fmt.Sprintf("array_field_%d", i)] = arr
 	}
@@ -133,7 +208,7 @@ func generateLargeContent() models.Content {
 	return content
 }
 
-func getRandomUserAgent() string {
+func (g *Generator) getRandomUserAgent() string {
 	userAgents := []string{
 		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
 		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36",
@@ -141,105 +216,105 @@ func getRandomUserAgent() string {
 		"Mozilla/5.0 (iPhone; CPU iPhone OS 14_7_1 like Mac OS X)",
 		"Mozilla/5.0 (Android 11; Mobile; rv:68.0) Gecko/68.0 Firefox/88.0",
 	}
-	return userAgents[rand.Intn(len(userAgents))]
+	return userAgents[g.rng.Intn(len(userAgents))]
 }
 
-func getRandomAction() string {
+func (g *Generator) getRandomAction() string {
 	actions := []string{
 		"login", "logout", "view", "click", "purchase", "search", "filter",
 		"create", "update", "delete", "download", "upload", "share", "comment",
 		"like", "dislike", "subscribe", "unsubscribe", "follow", "unfollow",
 	}
-	return actions[rand.Intn(len(actions))]
+	return actions[g.rng.Intn(len(actions))]
 }
 
-func getRandomStatus() string {
+func (g *Generator) getRandomStatus() string {
 	statuses := []string{
 		"success", "failure", "pending", "timeout", "error", "warning", "info",
 	}
-	return statuses[rand.Intn(len(statuses))]
+	return statuses[g.rng.Intn(len(statuses))]
 }
 
-func getRandomProtocol() string {
+func (g *Generator) getRandomProtocol() string {
 	protocols := []string{"HTTP", "HTTPS", "WebSocket", "gRPC", "TCP", "UDP"}
-	return protocols[rand.Intn(len(protocols))]
+	return protocols[g.rng.Intn(len(protocols))]
 }
 
-func getRandomErrorCode() string {
+func (g *Generator) getRandomErrorCode() string {
 	codes := []string{"200", "201", "400", "401", "403", "404", "500", "502", "503", "504"}
-	return codes[rand.Intn(len(codes))]
+	return codes[g.rng.Intn(len(codes))]
 }
 
-func getRandomRegion() string {
+func (g *Generator) getRandomRegion() string {
 	regions := []string{"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1", "ap-northeast-1"}
-	return regions[rand.Intn(len(regions))]
+	return regions[g.rng.Intn(len(regions))]
 }
 
-func getRandomEnvironment() string {
+func (g *Generator) getRandomEnvironment() string {
 	envs := []string{"production", "staging", "development", "testing"}
-	return envs[rand.Intn(len(envs))]
+	return envs[g.rng.Intn(len(envs))]
 }
 
-func getRandomFeatureFlag() string {
+func (g *Generator) getRandomFeatureFlag() string {
 	flags := []string{"new_ui", "beta_search", "advanced_analytics", "real_time_sync", "auto_backup"}
-	return flags[rand.Intn(len(flags))]
+	return flags[g.rng.Intn(len(flags))]
 }
 
-func getRandomSegment() string {
+func (g *Generator) getRandomSegment() string {
 	segments := []string{"premium", "basic", "trial", "enterprise", "free"}
-	return segments[rand.Intn(len(segments))]
+	return segments[g.rng.Intn(len(segments))]
 }
 
-func getRandomTier() string {
+func (g *Generator) getRandomTier() string {
 	tiers := []string{"bronze", "silver", "gold", "platinum", "diamond"}
-	return tiers[rand.Intn(len(tiers))]
+	return tiers[g.rng.Intn(len(tiers))]
 }
 
-func getRandomPlan() string {
+func (g *Generator) getRandomPlan() string {
 	plans := []string{"starter", "professional", "business", "enterprise", "custom"}
-	return plans[rand.Intn(len(plans))]
+	return plans[g.rng.Intn(len(plans))]
 }
 
-func getRandomCountry() string {
+func (g *Generator) getRandomCountry() string {
 	countries := []string{"US", "UK", "CA", "AU", "DE", "FR", "JP", "SG", "IN", "BR"}
-	return countries[rand.Intn(len(countries))]
+	return countries[g.rng.Intn(len(countries))]
 }
 
-func getRandomCity() string {
+func (g *Generator) getRandomCity() string {
 	cities := []string{"New York", "London", "Toronto", "Sydney", "Berlin", "Paris", "Tokyo", "Singapore", "Mumbai", "São Paulo"}
-	return cities[rand.Intn(len(cities))]
+	return cities[g.rng.Intn(len(cities))]
 }
 
-func getRandomTimezone() string {
+func (g *Generator) getRandomTimezone() string {
 	timezones := []string{"UTC", "America/New_York", "Europe/London", "Asia/Tokyo", "Australia/Sydney"}
-	return timezones[rand.Intn(len(timezones))]
+	return timezones[g.rng.Intn(len(timezones))]
 }
 
-func getRandomLanguage() string {
+func (g *Generator) getRandomLanguage() string {
 	languages := []string{"en", "es", "fr", "de", "ja", "zh", "pt", "ru", "ar", "hi"}
-	return languages[rand.Intn(len(languages))]
+	return languages[g.rng.Intn(len(languages))]
 }
 
-func getRandomCurrency() string {
+func (g *Generator) getRandomCurrency() string {
 	currencies := []string{"USD", "EUR", "GBP", "JPY", "CAD", "AUD", "CHF", "CNY", "INR", "BRL"}
-	return currencies[rand.Intn(len(currencies))]
+	return currencies[g.rng.Intn(len(currencies))]
 }
 
-func generateJapaneseString(length int) string {
+func (g *Generator) generateJapaneseString(length int) string {
 	// Hiragana: 0x3040 - 0x309F
 	// Katakana: 0x30A0 - 0x30FF
 	// Kanji: 0x4E00 - 0x9FAF
 
 	runes := make([]rune, length)
 	for i := 0; i < length; i++ {
-		type_ := rand.Intn(3)
+		type_ := g.rng.Intn(3)
 		switch type_ {
 		case 0: // Hiragana
-			runes[i] = rune(0x3040 + rand.Intn(0x309F-0x3040+1))
+			runes[i] = rune(0x3040 + g.rng.Intn(0x309F-0x3040+1))
 		case 1: // Katakana
-			runes[i] = rune(0x30A0 + rand.Intn(0x30FF-0x30A0+1))
+			runes[i] = rune(0x30A0 + g.rng.Intn(0x30FF-0x30A0+1))
 		case 2: // Kanji (subset for simplicity)
-			runes[i] = rune(0x4E00 + rand.Intn(0x1000))
+			runes[i] = rune(0x4E00 + g.rng.Intn(0x1000))
 		}
 	}
 	return string(runes)
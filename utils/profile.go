@@ -0,0 +1,409 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"log-project/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field types supported in a content profile.
+const (
+	FieldString    = "string"
+	FieldInt       = "int"
+	FieldFloat     = "float"
+	FieldBool      = "bool"
+	FieldUUID      = "uuid"
+	FieldTimestamp = "timestamp"
+	FieldEnum      = "enum"
+	FieldObject    = "object"
+	FieldArray     = "array"
+)
+
+// String charsets.
+const (
+	CharsetAlpha        = "alpha"
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetHex          = "hex"
+	CharsetWords        = "words"
+	CharsetJapanese     = "japanese"
+)
+
+// Limits that keep a single generated document to a sane size, since profiles
+// can be submitted through the API.
+const (
+	maxProfileDepth  = 16
+	maxProfileRepeat = 1000
+	maxProfileItems  = 1000
+	maxProfileLength = 100000
+)
+
+// maxProfileInt bounds int min/max to the integers a float64 holds exactly,
+// which also keeps max-min+1 within an int64.
+const maxProfileInt = 1 << 53
+
+// Profile describes the shape of generated log content, as an alternative to
+// the built-in small/medium/large shapes.
+type Profile struct {
+	Name   string      `json:"name" yaml:"name"`
+	Fields []FieldSpec `json:"fields" yaml:"fields"`
+}
+
+// FieldSpec describes one field of a profile. Which options apply depends on
+// Type:
+//
+//   - string: MinLength/MaxLength (characters, or words for the words
+//     charset), Charset, Cardinality
+//   - int, float: Min/Max (within ±2^53 for int), Cardinality
+//   - enum: Values
+//   - object: Fields
+//   - array: Items, MinItems/MaxItems
+//
+// Cardinality > 0 limits the field to that many distinct values, which is
+// what decides index selectivity. Repeat > 1 expands the field into Repeat
+// fields named name_0 ... name_N-1, which makes wide payloads easy to write.
+// NullRate is the probability that the field is omitted from a document.
+type FieldSpec struct {
+	Name        string      `json:"name" yaml:"name"`
+	Type        string      `json:"type" yaml:"type"`
+	MinLength   int         `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength   int         `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Charset     string      `json:"charset,omitempty" yaml:"charset,omitempty"`
+	Min         float64     `json:"min,omitempty" yaml:"min,omitempty"`
+	Max         float64     `json:"max,omitempty" yaml:"max,omitempty"`
+	Values      []string    `json:"values,omitempty" yaml:"values,omitempty"`
+	Cardinality int         `json:"cardinality,omitempty" yaml:"cardinality,omitempty"`
+	Fields      []FieldSpec `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Items       *FieldSpec  `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems    int         `json:"min_items,omitempty" yaml:"min_items,omitempty"`
+	MaxItems    int         `json:"max_items,omitempty" yaml:"max_items,omitempty"`
+	Repeat      int         `json:"repeat,omitempty" yaml:"repeat,omitempty"`
+	NullRate    float64     `json:"null_rate,omitempty" yaml:"null_rate,omitempty"`
+}
+
+// LoadProfile reads a profile from a .json, .yaml or .yml file.
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+	p, err := ParseProfile(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// ParseProfile decodes a profile in the given format ("json" or "yaml") and
+// validates it. Unknown keys are rejected so typos do not silently fall back
+// to defaults.
+func ParseProfile(data []byte, format string) (*Profile, error) {
+	var p Profile
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid profile: %w", err)
+		}
+	case "yaml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid profile: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown profile format %q", format)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks field types and option ranges.
+func (p *Profile) Validate() error {
+	if len(p.Fields) == 0 {
+		return fmt.Errorf("profile must define at least one field")
+	}
+	return validateFields(p.Fields, "", 1)
+}
+
+func validateFields(fields []FieldSpec, prefix string, depth int) error {
+	seen := make(map[string]bool)
+	for i := range fields {
+		f := &fields[i]
+		if f.Name == "" {
+			return fmt.Errorf("field %d of %q has no name", i, strings.TrimSuffix(prefix, "."))
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("duplicate field %q", prefix+f.Name)
+		}
+		seen[f.Name] = true
+		if err := validateField(f, prefix+f.Name, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(f *FieldSpec, path string, depth int) error {
+	if depth > maxProfileDepth {
+		return fmt.Errorf("field %q: nesting deeper than %d levels", path, maxProfileDepth)
+	}
+	if f.Repeat < 0 || f.Cardinality < 0 || f.MinLength < 0 || f.MaxLength < 0 || f.MinItems < 0 || f.MaxItems < 0 {
+		return fmt.Errorf("field %q: counts and lengths must not be negative", path)
+	}
+	if f.Repeat > maxProfileRepeat || f.MaxItems > maxProfileItems || f.MinItems > maxProfileItems ||
+		f.MaxLength > maxProfileLength || f.MinLength > maxProfileLength {
+		return fmt.Errorf("field %q: repeat and item counts are limited to %d, lengths to %d", path, maxProfileRepeat, maxProfileLength)
+	}
+	if f.MaxLength != 0 && f.MaxLength < f.MinLength {
+		return fmt.Errorf("field %q: max_length is less than min_length", path)
+	}
+	if f.MaxItems != 0 && f.MaxItems < f.MinItems {
+		return fmt.Errorf("field %q: max_items is less than min_items", path)
+	}
+	if math.IsNaN(f.Min) || math.IsNaN(f.Max) || math.IsInf(f.Min, 0) || math.IsInf(f.Max, 0) {
+		return fmt.Errorf("field %q: min and max must be finite numbers", path)
+	}
+	if f.Max < f.Min {
+		return fmt.Errorf("field %q: max is less than min", path)
+	}
+	if f.Type == FieldInt && (math.Abs(f.Min) > maxProfileInt || math.Abs(f.Max) > maxProfileInt) {
+		return fmt.Errorf("field %q: int min and max are limited to ±%d", path, int64(maxProfileInt))
+	}
+	if f.NullRate < 0 || f.NullRate >= 1 {
+		return fmt.Errorf("field %q: null_rate must be between 0 and 1", path)
+	}
+
+	switch f.Type {
+	case FieldString:
+		switch f.Charset {
+		case "", CharsetAlpha, CharsetAlphanumeric, CharsetHex, CharsetWords, CharsetJapanese:
+		default:
+			return fmt.Errorf("field %q: unknown charset %q", path, f.Charset)
+		}
+	case FieldInt, FieldFloat, FieldBool, FieldUUID, FieldTimestamp:
+	case FieldEnum:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("field %q: enum needs values", path)
+		}
+	case FieldObject:
+		if len(f.Fields) == 0 {
+			return fmt.Errorf("field %q: object needs fields", path)
+		}
+		return validateFields(f.Fields, path+".", depth+1)
+	case FieldArray:
+		if f.Items == nil {
+			return fmt.Errorf("field %q: array needs items", path)
+		}
+		return validateField(f.Items, path+"[]", depth+1)
+	default:
+		return fmt.Errorf("field %q: unknown type %q", path, f.Type)
+	}
+	return nil
+}
+
+// GenerateProfileContent builds one document shaped like p.
+func (g *Generator) GenerateProfileContent(p *Profile) models.Content {
+	content := make(models.Content, len(p.Fields))
+	g.fillFields(content, p.Fields, "")
+	return content
+}
+
+func (g *Generator) fillFields(dst map[string]interface{}, fields []FieldSpec, prefix string) {
+	for i := range fields {
+		f := &fields[i]
+		if f.Repeat > 1 {
+			for r := 0; r < f.Repeat; r++ {
+				name := fmt.Sprintf("%s_%d", f.Name, r)
+				if f.NullRate > 0 && g.rng.Float64() < f.NullRate {
+					continue
+				}
+				dst[name] = g.fieldValue(f, prefix+name)
+			}
+			continue
+		}
+		if f.NullRate > 0 && g.rng.Float64() < f.NullRate {
+			continue
+		}
+		dst[f.Name] = g.fieldValue(f, prefix+f.Name)
+	}
+}
+
+// fieldValue generates a value for f. path identifies the field so that
+// fields with a Cardinality draw from their own stable set of values.
+func (g *Generator) fieldValue(f *FieldSpec, path string) interface{} {
+	if f.Cardinality > 0 && f.Type != FieldObject && f.Type != FieldArray && f.Type != FieldEnum {
+		// Value number k is generated from a generator seeded by the field
+		// path and k, so every batch (and every run) shares the same set.
+		// A splitmix source keeps this cheap: seeding a rand.NewSource costs
+		// more than generating the value itself.
+		k := g.rng.Intn(f.Cardinality)
+		h := fnv.New64a()
+		h.Write([]byte(path))
+		vg := &Generator{
+			rng:  rand.New(&splitMixSource{state: uint64(DeriveSeed(int64(h.Sum64()), int64(k)))}),
+			base: g.base,
+		}
+		return vg.rawValue(f, path)
+	}
+	return g.rawValue(f, path)
+}
+
+// intSpan returns how many integers lie in [lo, hi], or false when there are
+// none or the count does not fit in an int64.
+func intSpan(lo, hi int64) (int64, bool) {
+	d := hi - lo
+	if hi < lo || d < 0 || d == math.MaxInt64 {
+		return 0, false
+	}
+	return d + 1, true
+}
+
+func (g *Generator) rawValue(f *FieldSpec, path string) interface{} {
+	switch f.Type {
+	case FieldString:
+		minLen, maxLen := f.MinLength, f.MaxLength
+		if maxLen == 0 {
+			maxLen = minLen
+			if maxLen == 0 {
+				minLen, maxLen = 8, 16
+			}
+		}
+		return g.randomString(f.Charset, minLen+g.rng.Intn(maxLen-minLen+1))
+	case FieldInt:
+		lo, hi := int64(f.Min), int64(f.Max)
+		if hi == 0 && lo == 0 {
+			hi = 1000000
+		}
+		span, ok := intSpan(lo, hi)
+		if !ok {
+			// Validate rejects such ranges; draw from the low end rather
+			// than panic in Int63n
+			span = math.MaxInt64
+		}
+		return lo + g.rng.Int63n(span)
+	case FieldFloat:
+		lo, hi := f.Min, f.Max
+		if hi == 0 && lo == 0 {
+			hi = 1
+		}
+		return lo + g.rng.Float64()*(hi-lo)
+	case FieldBool:
+		return g.rng.Intn(2) == 1
+	case FieldUUID:
+		return g.UUID().String()
+	case FieldTimestamp:
+		// Within the 30 days before the base time, like created_at.
+		return g.base.Add(-time.Duration(g.rng.Int63n(int64(30 * 24 * time.Hour)))).Format(time.RFC3339)
+	case FieldEnum:
+		return f.Values[g.rng.Intn(len(f.Values))]
+	case FieldObject:
+		obj := make(map[string]interface{}, len(f.Fields))
+		g.fillFields(obj, f.Fields, path+".")
+		return obj
+	case FieldArray:
+		minItems, maxItems := f.MinItems, f.MaxItems
+		if maxItems == 0 {
+			maxItems = minItems
+			if maxItems == 0 {
+				minItems, maxItems = 1, 5
+			}
+		}
+		n := minItems + g.rng.Intn(maxItems-minItems+1)
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = g.fieldValue(f.Items, path+"[]")
+		}
+		return items
+	}
+	return nil
+}
+
+// splitMixSource is a tiny rand.Source64 (SplitMix64) that is free to seed.
+type splitMixSource struct {
+	state uint64
+}
+
+func (s *splitMixSource) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+func (s *splitMixSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *splitMixSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// wordList feeds the "words" charset so that full-text search has real,
+// repeatable terms to match.
+var wordList = []string{
+	"error", "warning", "timeout", "request", "response", "user", "session",
+	"login", "logout", "payment", "order", "checkout", "cart", "search",
+	"upload", "download", "retry", "failed", "success", "cache", "miss", "hit",
+	"database", "query", "slow", "connection", "refused", "reset", "token",
+	"expired", "invalid", "granted", "denied", "queue", "worker", "job",
+	"started", "finished", "latency", "throughput", "memory", "disk", "cpu",
+	"network", "packet", "dropped", "service", "gateway", "upstream", "click",
+}
+
+const (
+	alphaChars        = "abcdefghijklmnopqrstuvwxyz"
+	alphanumericChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	hexChars          = "0123456789abcdef"
+)
+
+// randomString returns a string of length characters (runes) in charset. For
+// the words charset length counts words rather than characters.
+func (g *Generator) randomString(charset string, length int) string {
+	switch charset {
+	case CharsetWords:
+		words := make([]string, length)
+		for i := range words {
+			words[i] = wordList[g.rng.Intn(len(wordList))]
+		}
+		return strings.Join(words, " ")
+	case CharsetJapanese:
+		return g.generateJapaneseString(length)
+	}
+
+	chars := alphaChars
+	switch charset {
+	case CharsetAlphanumeric:
+		chars = alphanumericChars
+	case CharsetHex:
+		chars = hexChars
+	}
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = chars[g.rng.Intn(len(chars))]
+	}
+	return string(b)
+}
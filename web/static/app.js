@@ -1,10 +1,18 @@
 // Global variables
 let currentPage = 1;
 let totalPages = 1;
+let currentJobId = null;
+let jobPollTimer = null;
+
+const JOB_POLL_INTERVAL = 1000;
 
 // DOM elements
 const elements = {
     initBtn: document.getElementById('initBtn'),
+    cancelJobBtn: document.getElementById('cancelJobBtn'),
+    jobProgress: document.getElementById('jobProgress'),
+    jobProgressBar: document.getElementById('jobProgressBar'),
+    jobProgressText: document.getElementById('jobProgressText'),
     filterBtn: document.getElementById('filterBtn'),
     clearBtn: document.getElementById('clearBtn'),
     truncateBtn: document.getElementById('truncateBtn'),
@@ -24,6 +32,7 @@ const elements = {
 // Event listeners
 document.addEventListener('DOMContentLoaded', () => {
     elements.initBtn.addEventListener('click', initializeData);
+    elements.cancelJobBtn.addEventListener('click', cancelJob);
     elements.filterBtn.addEventListener('click', applyFilters);
     elements.clearBtn.addEventListener('click', clearFilters);
     elements.truncateBtn.addEventListener('click', truncateDatabase);
@@ -31,6 +40,7 @@ document.addEventListener('DOMContentLoaded', () => {
 
     // Initial load
     loadLogs();
+    resumeRunningJob();
 });
 
 // API functions
@@ -45,7 +55,9 @@ async function apiCall(url, options = {}) {
         });
 
         if (!response.ok) {
-            throw new Error(`HTTP error! status: ${response.status}`);
+            // Show the API's own message (e.g. a content search syntax error) when there is one
+            const body = await response.json().catch(() => null);
+            throw new Error(body && body.error ? body.error : `HTTP error! status: ${response.status}`);
         }
 
         return await response.json();
@@ -60,29 +72,138 @@ async function apiCall(url, options = {}) {
 async function initializeData() {
     const recordCount = document.getElementById('recordCount').value;
     const contentSize = document.getElementById('contentSize').value;
+    const userDistribution = document.getElementById('userDistribution').value;
+    const timePattern = document.getElementById('timePattern').value;
+    const targetRowBytes = document.getElementById('targetRowBytes').value;
+
+    const request = {
+        record_count: parseInt(recordCount),
+        content_size: contentSize,
+        user_distribution: { type: userDistribution },
+        time_pattern: timePattern
+    };
+    if (targetRowBytes) {
+        request.target_row_bytes = parseInt(targetRowBytes);
+    }
 
-    elements.initBtn.disabled = true;
-    elements.initBtn.innerHTML = '<i class="fas fa-spinner fa-spin me-2"></i>Initializing...';
+    setInitRunning(true);
 
     try {
         const result = await apiCall('/api/initialize', {
             method: 'POST',
-            body: JSON.stringify({
-                record_count: parseInt(recordCount),
-                content_size: contentSize
-            })
+            body: JSON.stringify(request)
         });
 
-        showAlert(`Successfully initialized ${result.record_count} records in ${result.duration}`, 'success');
-        loadLogs();
+        trackJob(result.job_id);
     } catch (error) {
         showAlert('Failed to initialize data', 'danger');
+        setInitRunning(false);
+    }
+}
+
+// Pick up a job started before the page was (re)loaded
+async function resumeRunningJob() {
+    try {
+        const result = await fetch('/api/jobs').then(r => r.json());
+        const running = (result.data || []).find(job => job.status === 'running');
+        if (running) {
+            setInitRunning(true);
+            trackJob(running.id);
+        }
+    } catch (error) {
+        console.error('Failed to load jobs:', error);
+    }
+}
+
+// Poll a seeding job until it finishes
+function trackJob(jobId) {
+    currentJobId = jobId;
+    elements.jobProgress.classList.remove('d-none');
+    updateJobProgress({ percent: 0, rows_inserted: 0, record_count: 0 });
+    pollJob();
+}
+
+async function pollJob() {
+    if (!currentJobId) return;
+
+    let job;
+    try {
+        const response = await fetch(`/api/jobs/${currentJobId}`);
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+        job = await response.json();
+    } catch (error) {
+        console.error('Failed to poll job:', error);
+        jobPollTimer = setTimeout(pollJob, JOB_POLL_INTERVAL);
+        return;
+    }
+
+    updateJobProgress(job);
+
+    if (job.status === 'running') {
+        jobPollTimer = setTimeout(pollJob, JOB_POLL_INTERVAL);
+        return;
+    }
+
+    finishJob(job);
+}
+
+function updateJobProgress(job) {
+    const percent = Math.min(100, job.percent || 0);
+    elements.jobProgressBar.style.width = `${percent}%`;
+    elements.jobProgressBar.textContent = `${percent.toFixed(1)}%`;
+
+    let text = `${(job.rows_inserted || 0).toLocaleString()} / ${(job.record_count || 0).toLocaleString()} rows`;
+    if (job.total_batches) {
+        text += ` · batch ${job.batch}/${job.total_batches}`;
+    }
+    if (job.records_per_second) {
+        text += ` · ${Math.round(job.records_per_second).toLocaleString()} rows/s`;
+    }
+    if (job.eta) {
+        text += ` · ETA ${job.eta}`;
+    }
+    elements.jobProgressText.textContent = text;
+}
+
+function finishJob(job) {
+    currentJobId = null;
+    clearTimeout(jobPollTimer);
+    setInitRunning(false);
+    elements.jobProgress.classList.add('d-none');
+
+    if (job.status === 'completed') {
+        showAlert(`Successfully initialized ${job.rows_inserted} records in ${job.duration}`, 'success');
+    } else if (job.status === 'cancelled') {
+        showAlert(`Initialization cancelled after ${job.rows_inserted} records`, 'warning');
+    } else {
+        showAlert(`Failed to initialize data: ${job.error}`, 'danger');
+    }
+    loadLogs();
+}
+
+// Cancel the running seeding job
+async function cancelJob() {
+    if (!currentJobId) return;
+
+    elements.cancelJobBtn.disabled = true;
+    try {
+        await apiCall(`/api/jobs/${currentJobId}`, { method: 'DELETE' });
+    } catch (error) {
+        showAlert('Failed to cancel job', 'danger');
     } finally {
-        elements.initBtn.disabled = false;
-        elements.initBtn.innerHTML = '<i class="fas fa-play me-2"></i>Initialize';
+        elements.cancelJobBtn.disabled = false;
     }
 }
 
+function setInitRunning(running) {
+    elements.initBtn.disabled = running;
+    elements.initBtn.innerHTML = running
+        ? '<i class="fas fa-spinner fa-spin me-2"></i>Initializing...'
+        : '<i class="fas fa-play me-2"></i>Initialize';
+}
+
 // Load logs with filters
 async function loadLogs(page = 1) {
     const filters = getFilters();
@@ -126,7 +247,11 @@ function clearFilters() {
     document.getElementById('createdAt').value = '';
     document.getElementById('createdAtTo').value = '';
     document.getElementById('contentLike').value = '';
+    document.getElementById('field').value = '';
+    document.getElementById('jsonPath').value = '';
     document.getElementById('searchType').value = 'fulltext';
+    document.getElementById('tsConfig').value = 'english';
+    document.getElementById('sortOrder').value = 'created_at';
 
     currentPage = 1;
     loadLogs(1);
@@ -148,14 +273,30 @@ function getFilters() {
     const createdAtTo = document.getElementById('createdAtTo').value;
     if (createdAtTo) filters.created_at_to = createdAtTo;
 
+    const jsonPath = document.getElementById('jsonPath').value;
+    if (jsonPath) filters.jsonpath = jsonPath;
+
     const contentLike = document.getElementById('contentLike').value;
     const searchType = document.getElementById('searchType').value;
 
     if (contentLike) {
+        const field = document.getElementById('field').value;
+        if (field) filters.field = field;
+
         if (searchType === 'partial') {
             filters.search_term = contentLike;
         } else {
             filters.content_like = contentLike;
+            if (searchType === 'websearch') {
+                filters.syntax = 'websearch';
+            }
+            const tsConfig = document.getElementById('tsConfig').value;
+            if (tsConfig !== 'english') {
+                filters.ts_config = tsConfig;
+            }
+            if (document.getElementById('sortOrder').value === 'relevance') {
+                filters.sort = 'relevance';
+            }
         }
     }
 
@@ -181,6 +322,7 @@ function displayLogs(logs) {
             <td><span class="badge bg-secondary">${log.action}</span></td>
             <td>${formatDate(log.created_at)}</td>
             <td>
+                ${log.headline ? `<div class="small mb-1" title="rank ${log.rank}">${highlightSnippet(log.headline)}</div>` : ''}
                 <button class="btn btn-sm btn-outline-primary" onclick="showContent(${JSON.stringify(log.content).replace(/"/g, '&quot;')})">
                     <i class="fas fa-eye"></i> View
                 </button>
@@ -189,6 +331,23 @@ function displayLogs(logs) {
     `).join('');
 }
 
+// Escape a ts_headline snippet, then turn its <mark> tags back into markup;
+// everything else from the content stays plain text
+function highlightSnippet(headline) {
+    return escapeHtml(headline)
+        .replace(/&lt;mark&gt;/g, '<mark>')
+        .replace(/&lt;\/mark&gt;/g, '</mark>');
+}
+
+function escapeHtml(text) {
+    return String(text)
+        .replace(/&/g, '&amp;')
+        .replace(/</g, '&lt;')
+        .replace(/>/g, '&gt;')
+        .replace(/"/g, '&quot;')
+        .replace(/'/g, '&#39;');
+}
+
 // Show content in modal
 function showContent(content) {
     elements.contentDisplay.textContent = JSON.stringify(content, null, 2);